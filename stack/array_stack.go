@@ -71,7 +71,7 @@ func (as *ArrayStack[E]) Slice() []E {
 		return nil
 	}
 
-	as.mu.RLocker()
+	as.mu.RLock()
 	defer as.mu.RUnlock()
 
 	if len(as.elems) == 0 {
@@ -136,3 +136,172 @@ func (as *ArrayStack[E]) PushMany(elems []E) error {
 
 	return nil
 }
+
+// Peek returns the element on top of the stack without removing it.
+//
+// Returns:
+//   - E: The element on top of the stack.
+//   - error: An error if the stack is nil or empty.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - ErrEmptyStack: If the stack is empty.
+func (as *ArrayStack[E]) Peek() (E, error) {
+	if as == nil {
+		return *new(E), common.ErrNilReceiver
+	}
+
+	as.mu.RLock()
+	defer as.mu.RUnlock()
+
+	if len(as.elems) == 0 {
+		return *new(E), ErrEmptyStack
+	}
+
+	return as.elems[len(as.elems)-1], nil
+}
+
+// Len returns the number of elements in the stack.
+//
+// Returns:
+//   - int: The number of elements in the stack. Zero if the receiver is nil.
+func (as *ArrayStack[E]) Len() int {
+	if as == nil {
+		return 0
+	}
+
+	as.mu.RLock()
+	defer as.mu.RUnlock()
+
+	return len(as.elems)
+}
+
+// PopN pops n elements from the stack at once. Either all of them are popped
+// or the stack is left unchanged.
+//
+// Parameters:
+//   - n: The number of elements to pop.
+//
+// Returns:
+//   - []E: The popped elements, from the top of the stack downwards. Nil if n
+//     is zero or if an error occurred.
+//   - error: An error if the elements could not be popped.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If n is negative.
+//   - ErrEmptyStack: If the stack has fewer than n elements.
+func (as *ArrayStack[E]) PopN(n int) ([]E, error) {
+	if as == nil {
+		return nil, common.ErrNilReceiver
+	} else if n < 0 {
+		err := common.NewErrBadParam("n", "must not be negative")
+		return nil, err
+	} else if n == 0 {
+		return nil, nil
+	}
+
+	as.mu.Lock()
+	defer as.mu.Unlock()
+
+	if len(as.elems) < n {
+		return nil, ErrEmptyStack
+	}
+
+	slice := as.popN(n)
+
+	return slice, nil
+}
+
+// popN removes the n elements on top of the stack. The caller must hold the
+// lock.
+//
+// Parameters:
+//   - n: The number of elements to remove. Assumed to be at most the size of
+//     the stack.
+//
+// Returns:
+//   - []E: The removed elements, from the top of the stack downwards. Nil if
+//     n is zero.
+func (as *ArrayStack[E]) popN(n int) []E {
+	if n == 0 {
+		return nil
+	}
+
+	tail := as.elems[len(as.elems)-n:]
+
+	slice := make([]E, n)
+	copy(slice, tail)
+
+	internal.Reverse(slice)
+
+	clear(tail)
+	as.elems = as.elems[:len(as.elems)-n]
+
+	return slice
+}
+
+// PopWhile pops the elements on top of the stack as long as they satisfy the
+// predicate. The elements are checked and popped at once: the predicate is
+// called while the stack is locked, so it must not use the stack or it
+// deadlocks.
+//
+// Parameters:
+//   - pred: The predicate the popped elements satisfy.
+//
+// Returns:
+//   - []E: The popped elements, from the top of the stack downwards. Nil if
+//     none was popped.
+//   - error: An error if the receiver or the predicate is nil.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If the predicate is nil.
+func (as *ArrayStack[E]) PopWhile(pred func(e E) bool) ([]E, error) {
+	if as == nil {
+		return nil, common.ErrNilReceiver
+	} else if pred == nil {
+		err := common.NewErrNilParam("pred")
+		return nil, err
+	}
+
+	as.mu.Lock()
+	defer as.mu.Unlock()
+
+	var n int
+
+	for n < len(as.elems) && pred(as.elems[len(as.elems)-1-n]) {
+		n++
+	}
+
+	slice := as.popN(n)
+
+	return slice, nil
+}
+
+// dropBottom removes the n bottom-most elements of the stack.
+//
+// Parameters:
+//   - n: The number of elements to remove. Assumed to be at most the size of
+//     the stack.
+//
+// Returns:
+//   - error: An error if the receiver is nil.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+func (as *ArrayStack[E]) dropBottom(n int) error {
+	if as == nil {
+		return common.ErrNilReceiver
+	}
+
+	as.mu.Lock()
+	defer as.mu.Unlock()
+
+	size := copy(as.elems, as.elems[n:])
+
+	clear(as.elems[size:])
+	as.elems = as.elems[:size]
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package stack
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestArrayStackSlice checks that Slice returns the elements from the top to
+// the bottom and can be called on a non-empty stack, which used to release a
+// read lock it did not hold.
+func TestArrayStackSlice(t *testing.T) {
+	as := new(ArrayStack[int])
+
+	for i := 1; i <= 3; i++ {
+		err := as.Push(i)
+		if err != nil {
+			t.Fatalf("Push(%d) returned %v", i, err)
+		}
+	}
+
+	got := as.Slice()
+	want := []int{3, 2, 1}
+
+	if !slices.Equal(got, want) {
+		t.Fatalf("Slice() = %v, want %v", got, want)
+	}
+
+	// The lock must still be usable afterwards.
+	err := as.Push(4)
+	if err != nil {
+		t.Fatalf("Push(4) returned %v", err)
+	}
+
+	if got := as.Slice(); len(got) != 4 {
+		t.Fatalf("Slice() = %v, want 4 elements", got)
+	}
+}
+
+// TestArrayStackPeekStack checks Peek, Len, PopN and PopWhile.
+func TestArrayStackPeekStack(t *testing.T) {
+	var as PeekStack[int] = new(ArrayStack[int])
+
+	_, err := as.Peek()
+	if err != ErrEmptyStack {
+		t.Fatalf("Peek() on an empty stack returned %v", err)
+	}
+
+	_ = Push[int](as, []int{1, 2, 3, 4, 5})
+
+	top, err := as.Peek()
+	if err != nil || top != 1 || as.Len() != 5 {
+		t.Fatalf("Peek() = %d, %v with Len() = %d, want 1, nil with 5", top, err, as.Len())
+	}
+
+	_, err = as.PopN(6)
+	if err != ErrEmptyStack || as.Len() != 5 {
+		t.Fatalf("PopN(6) = %v and left %d elements, want %v and 5", err, as.Len(), ErrEmptyStack)
+	}
+
+	elems, err := as.PopN(2)
+	if err != nil || !slices.Equal(elems, []int{1, 2}) {
+		t.Fatalf("PopN(2) = %v, %v, want [1 2], nil", elems, err)
+	}
+
+	elems, err = as.PopWhile(func(e int) bool { return e < 5 })
+	if err != nil || !slices.Equal(elems, []int{3, 4}) {
+		t.Fatalf("PopWhile() = %v, %v, want [3 4], nil", elems, err)
+	}
+
+	if got := as.Slice(); !slices.Equal(got, []int{5}) {
+		t.Fatalf("Slice() = %v, want [5]", got)
+	}
+}
@@ -0,0 +1,325 @@
+package stack
+
+import (
+	"context"
+	"iter"
+	"sync"
+
+	common "github.com/PlayerR9/mygo-data/common"
+	"github.com/PlayerR9/mygo-data/stack/internal"
+)
+
+// BlockingStack is a generic stack implemented using an array whose consumers
+// can wait for elements to be pushed instead of polling, such as the workers
+// of a LIFO scheduler. Once closed, no element can be pushed anymore, but the
+// remaining elements can still be popped.
+//
+// An empty stack can be created with the `bs := new(BlockingStack[E])`
+// constructor.
+type BlockingStack[E any] struct {
+	// elems is the underlying array.
+	elems []E
+
+	// closed is true once the stack is closed.
+	closed bool
+
+	// wake is closed to wake up the waiting consumers when an element is
+	// pushed or when the stack is closed. Nil if no consumer is waiting.
+	wake chan struct{}
+
+	// mu is the mutex for the stack.
+	mu sync.Mutex
+}
+
+// signal wakes up the waiting consumers. The caller must hold the lock.
+func (bs *BlockingStack[E]) signal() {
+	if bs.wake == nil {
+		return
+	}
+
+	close(bs.wake)
+	bs.wake = nil
+}
+
+// Push implements CoreStack. It fails with ErrClosedStack if the stack is
+// closed.
+func (bs *BlockingStack[E]) Push(e E) error {
+	if bs == nil {
+		return common.ErrNilReceiver
+	}
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	if bs.closed {
+		return ErrClosedStack
+	}
+
+	bs.elems = append(bs.elems, e)
+	bs.signal()
+
+	return nil
+}
+
+// pop removes the element on top of the stack. The caller must hold the lock.
+//
+// Returns:
+//   - E: The element on top of the stack.
+//   - bool: True if the stack was not empty, false otherwise.
+func (bs *BlockingStack[E]) pop() (E, bool) {
+	if len(bs.elems) == 0 {
+		return *new(E), false
+	}
+
+	e := bs.elems[len(bs.elems)-1]
+
+	bs.elems[len(bs.elems)-1] = *new(E)
+	bs.elems = bs.elems[:len(bs.elems)-1]
+
+	return e, true
+}
+
+// Pop implements CoreStack. It does not wait; see PopWait.
+func (bs *BlockingStack[E]) Pop() (E, error) {
+	if bs == nil {
+		return *new(E), common.ErrNilReceiver
+	}
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	e, ok := bs.pop()
+	if !ok {
+		return *new(E), ErrEmptyStack
+	}
+
+	return e, nil
+}
+
+// TryPop pops an element from the stack without waiting.
+//
+// Returns:
+//   - E: The element that was popped from the stack.
+//   - bool: True if an element was popped, false if the stack is empty or the
+//     receiver is nil.
+func (bs *BlockingStack[E]) TryPop() (E, bool) {
+	if bs == nil {
+		return *new(E), false
+	}
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	e, ok := bs.pop()
+	return e, ok
+}
+
+// Peek returns the element on top of the stack without removing it. It does
+// not wait.
+//
+// Returns:
+//   - E: The element on top of the stack.
+//   - error: An error if the stack is nil or empty.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - ErrEmptyStack: If the stack is empty.
+func (bs *BlockingStack[E]) Peek() (E, error) {
+	if bs == nil {
+		return *new(E), common.ErrNilReceiver
+	}
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	if len(bs.elems) == 0 {
+		return *new(E), ErrEmptyStack
+	}
+
+	return bs.elems[len(bs.elems)-1], nil
+}
+
+// PopWait pops an element from the stack, waiting for one to be pushed if the
+// stack is empty.
+//
+// Parameters:
+//   - ctx: The context of the wait.
+//
+// Returns:
+//   - E: The element that was popped from the stack.
+//   - error: An error if no element could be popped.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If the context is nil.
+//   - ErrClosedStack: If the stack is closed and empty, even while waiting.
+//   - any other error: The error of the context if it is done before an
+//     element is pushed.
+func (bs *BlockingStack[E]) PopWait(ctx context.Context) (E, error) {
+	if bs == nil {
+		return *new(E), common.ErrNilReceiver
+	} else if ctx == nil {
+		err := common.NewErrNilParam("ctx")
+		return *new(E), err
+	}
+
+	for {
+		bs.mu.Lock()
+
+		e, ok := bs.pop()
+		if ok {
+			bs.mu.Unlock()
+			return e, nil
+		} else if bs.closed {
+			bs.mu.Unlock()
+			return *new(E), ErrClosedStack
+		}
+
+		if bs.wake == nil {
+			bs.wake = make(chan struct{})
+		}
+
+		wake := bs.wake
+
+		bs.mu.Unlock()
+
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			return *new(E), ctx.Err()
+		}
+	}
+}
+
+// IsEmpty implements CoreStack.
+func (bs *BlockingStack[E]) IsEmpty() bool {
+	if bs == nil {
+		return true
+	}
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	ok := len(bs.elems) == 0
+	return ok
+}
+
+// Slice implements Collection.
+func (bs *BlockingStack[E]) Slice() []E {
+	if bs == nil {
+		return nil
+	}
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	if len(bs.elems) == 0 {
+		return nil
+	}
+
+	slice := make([]E, len(bs.elems))
+	copy(slice, bs.elems)
+
+	internal.Reverse(slice)
+
+	return slice
+}
+
+// Reset implements Collection. It does not reopen a closed stack.
+func (bs *BlockingStack[E]) Reset() error {
+	if bs == nil {
+		return common.ErrNilReceiver
+	}
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	if len(bs.elems) == 0 {
+		return nil
+	}
+
+	clear(bs.elems)
+	bs.elems = nil
+
+	return nil
+}
+
+// PushMany pushes all elements in the slice onto the stack in the order they are given in the slice.
+//
+// Parameters:
+//   - elems: The elements to push onto the stack.
+//
+// Returns:
+//   - error: An error if the elements could not be pushed onto the stack.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the stack is nil.
+//   - ErrClosedStack: If the stack is closed.
+func (bs *BlockingStack[E]) PushMany(elems []E) error {
+	if bs == nil {
+		return common.ErrNilReceiver
+	}
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	if bs.closed {
+		return ErrClosedStack
+	} else if len(elems) == 0 {
+		return nil
+	}
+
+	for i := len(elems) - 1; i >= 0; i-- {
+		bs.elems = append(bs.elems, elems[i])
+	}
+
+	bs.signal()
+
+	return nil
+}
+
+// Close closes the stack: later pushes fail with ErrClosedStack and the
+// consumers waiting on an empty stack are woken up with ErrClosedStack. The
+// remaining elements can still be popped. Closing a closed stack does
+// nothing.
+//
+// Returns:
+//   - error: An error if the receiver is nil.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+func (bs *BlockingStack[E]) Close() error {
+	if bs == nil {
+		return common.ErrNilReceiver
+	}
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	bs.closed = true
+	bs.signal()
+
+	return nil
+}
+
+// Drain returns an iterator that pops the elements of the stack, waiting for
+// new ones when it is empty, like a receive loop over a channel. The iteration
+// stops once the stack is closed and empty, or when the context is done. Since
+// the iterator cannot report errors, it yields nothing if the receiver or the
+// context is nil.
+//
+// Parameters:
+//   - ctx: The context of the waits. Must not be nil.
+//
+// Returns:
+//   - iter.Seq[E]: The iterator over the popped elements. Never returns nil.
+func (bs *BlockingStack[E]) Drain(ctx context.Context) iter.Seq[E] {
+	return func(yield func(E) bool) {
+		for {
+			e, err := bs.PopWait(ctx)
+			if err != nil || !yield(e) {
+				return
+			}
+		}
+	}
+}
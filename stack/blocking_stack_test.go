@@ -0,0 +1,308 @@
+package stack
+
+import (
+	"context"
+	"runtime"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// These tests are meant to be run with the race detector:
+//
+//	go test -race ./stack
+
+// waitForWaiters returns once a consumer waits on the stack.
+//
+// Parameters:
+//   - bs: The stack.
+func waitForWaiters(bs *BlockingStack[int]) {
+	for {
+		bs.mu.Lock()
+		waiting := bs.wake != nil
+		bs.mu.Unlock()
+
+		if waiting {
+			return
+		}
+
+		runtime.Gosched()
+	}
+}
+
+// TestBlockingStackPopWait checks that the consumers waiting on an empty stack
+// are woken up by pushes and that every element is popped exactly once.
+func TestBlockingStackPopWait(t *testing.T) {
+	const (
+		consumers = 8
+		per_cons  = 500
+	)
+
+	bs := new(BlockingStack[int])
+
+	seen := make([][]int, consumers)
+
+	var wg sync.WaitGroup
+
+	for c := range consumers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for range per_cons {
+				e, err := bs.PopWait(context.Background())
+				if err != nil {
+					t.Errorf("PopWait() = %v", err)
+					return
+				}
+
+				seen[c] = append(seen[c], e)
+			}
+		}()
+	}
+
+	waitForWaiters(bs)
+
+	for i := 0; i < consumers*per_cons; i += 4 {
+		if i%8 == 0 {
+			_ = bs.PushMany([]int{i, i + 1, i + 2, i + 3})
+		} else {
+			for j := range 4 {
+				_ = bs.Push(i + j)
+			}
+		}
+	}
+
+	wg.Wait()
+
+	var all []int
+
+	for _, s := range seen {
+		all = append(all, s...)
+	}
+
+	slices.Sort(all)
+
+	for i, e := range all {
+		if e != i {
+			t.Fatalf("sorted popped elements have %d at index %d: an element was lost or duplicated", e, i)
+		}
+	}
+
+	if len(all) != consumers*per_cons || !bs.IsEmpty() {
+		t.Fatalf("popped %d elements, want %d", len(all), consumers*per_cons)
+	}
+}
+
+// TestBlockingStackCancel checks that a PopWait returns the error of its
+// context once it is done and that the elements pushed afterwards are not
+// lost.
+func TestBlockingStackCancel(t *testing.T) {
+	bs := new(BlockingStack[int])
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	errs := make(chan error)
+
+	go func() {
+		_, err := bs.PopWait(ctx)
+		errs <- err
+	}()
+
+	waitForWaiters(bs)
+	cancel()
+
+	if err := <-errs; err != context.Canceled {
+		t.Fatalf("PopWait() = %v, want %v", err, context.Canceled)
+	}
+
+	_ = bs.Push(1)
+
+	if e, ok := bs.TryPop(); !ok || e != 1 {
+		t.Fatalf("TryPop() = %d, %t, want 1, true", e, ok)
+	}
+
+	// A done context does not prevent popping an available element.
+	_ = bs.Push(2)
+
+	if e, err := bs.PopWait(ctx); err != nil || e != 2 {
+		t.Fatalf("PopWait() with a done context = %d, %v, want 2, nil", e, err)
+	}
+
+	if _, err := bs.PopWait(nil); err == nil {
+		t.Fatal("PopWait(nil) succeeded")
+	}
+}
+
+// TestBlockingStackClose checks that Close wakes up every waiting consumer
+// with ErrClosedStack, that pushes then fail and that the remaining elements
+// can still be popped.
+func TestBlockingStackClose(t *testing.T) {
+	const consumers = 8
+
+	bs := new(BlockingStack[int])
+
+	errs := make(chan error, consumers)
+
+	for range consumers {
+		go func() {
+			_, err := bs.PopWait(context.Background())
+			errs <- err
+		}()
+	}
+
+	waitForWaiters(bs)
+
+	err := bs.Close()
+	if err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+
+	for range consumers {
+		if err := <-errs; err != ErrClosedStack {
+			t.Fatalf("PopWait() = %v after Close, want %v", err, ErrClosedStack)
+		}
+	}
+
+	bs = new(BlockingStack[int])
+
+	_ = bs.PushMany([]int{1, 2})
+	_ = bs.Close()
+
+	if err := bs.Push(3); err != ErrClosedStack {
+		t.Fatalf("Push() after Close = %v, want %v", err, ErrClosedStack)
+	}
+
+	if err := bs.PushMany([]int{3}); err != ErrClosedStack {
+		t.Fatalf("PushMany() after Close = %v, want %v", err, ErrClosedStack)
+	}
+
+	if err := bs.Close(); err != nil {
+		t.Fatalf("Close() of a closed stack = %v, want nil", err)
+	}
+
+	for _, want := range []int{1, 2} {
+		e, err := bs.PopWait(context.Background())
+		if err != nil || e != want {
+			t.Fatalf("PopWait() after Close = %d, %v, want %d, nil", e, err, want)
+		}
+	}
+
+	if _, err := bs.PopWait(context.Background()); err != ErrClosedStack {
+		t.Fatalf("PopWait() on a closed and empty stack = %v, want %v", err, ErrClosedStack)
+	}
+
+	// Reset does not reopen the stack.
+	_ = bs.Reset()
+
+	if err := bs.Push(1); err != ErrClosedStack {
+		t.Fatalf("Push() after Reset = %v, want %v", err, ErrClosedStack)
+	}
+}
+
+// TestBlockingStackDrain checks that concurrent Drain loops receive every
+// element exactly once and stop once the stack is closed and empty.
+func TestBlockingStackDrain(t *testing.T) {
+	const (
+		drainers = 4
+		total    = 4000
+	)
+
+	bs := new(BlockingStack[int])
+
+	seen := make([][]int, drainers)
+
+	var wg sync.WaitGroup
+
+	for d := range drainers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for e := range bs.Drain(context.Background()) {
+				seen[d] = append(seen[d], e)
+			}
+		}()
+	}
+
+	for i := range total {
+		_ = bs.Push(i)
+	}
+
+	_ = bs.Close()
+
+	wg.Wait()
+
+	var all []int
+
+	for _, s := range seen {
+		all = append(all, s...)
+	}
+
+	slices.Sort(all)
+
+	if len(all) != total {
+		t.Fatalf("drained %d elements, want %d", len(all), total)
+	}
+
+	for i, e := range all {
+		if e != i {
+			t.Fatalf("sorted drained elements have %d at index %d: an element was lost or duplicated", e, i)
+		}
+	}
+}
+
+// TestBlockingStackDrainStop checks that Drain stops when its context is done,
+// when the loop breaks, and yields nothing for a nil context.
+func TestBlockingStackDrainStop(t *testing.T) {
+	bs := new(BlockingStack[int])
+
+	_ = bs.PushMany([]int{1, 2, 3})
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan []int)
+
+	go func() {
+		var got []int
+
+		for e := range bs.Drain(ctx) {
+			got = append(got, e)
+		}
+
+		done <- got
+	}()
+
+	waitForWaiters(bs)
+	cancel()
+
+	if got := <-done; !slices.Equal(got, []int{1, 2, 3}) {
+		t.Fatalf("Drain() yielded %v before its context was done, want [1 2 3]", got)
+	}
+
+	_ = bs.PushMany([]int{1, 2, 3})
+
+	for e := range bs.Drain(context.Background()) {
+		if e != 1 {
+			t.Fatalf("Drain() yielded %d first, want 1", e)
+		}
+
+		break
+	}
+
+	if got := bs.Slice(); !slices.Equal(got, []int{2, 3}) {
+		t.Fatalf("Slice() = %v after breaking out of Drain, want [2 3]", got)
+	}
+
+	for e := range bs.Drain(nil) {
+		t.Fatalf("Drain(nil) yielded %d", e)
+	}
+
+	var nil_stack *BlockingStack[int]
+
+	for e := range nil_stack.Drain(context.Background()) {
+		t.Fatalf("Drain() on a nil stack yielded %d", e)
+	}
+}
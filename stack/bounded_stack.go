@@ -0,0 +1,405 @@
+package stack
+
+import (
+	"sync"
+
+	common "github.com/PlayerR9/mygo-data/common"
+)
+
+// OverflowPolicy is what a BoundedStack does when elements are pushed onto it
+// while it has no room left for them.
+type OverflowPolicy int
+
+const (
+	// OverflowError makes the push fail with ErrFullStack. The stack is not
+	// modified.
+	OverflowError OverflowPolicy = iota
+
+	// OverflowBlock makes the push wait until enough elements are popped.
+	OverflowBlock
+
+	// OverflowDropBottom makes the push drop the bottom-most elements of the
+	// stack to make room.
+	OverflowDropBottom
+)
+
+// bottomDropper is implemented by stacks that can remove their bottom-most
+// elements without being rebuilt.
+type bottomDropper interface {
+	// dropBottom removes the n bottom-most elements of the stack.
+	//
+	// Parameters:
+	//   - n: The number of elements to remove.
+	//
+	// Returns:
+	//   - error: An error if the elements could not be removed.
+	dropBottom(n int) error
+}
+
+// BoundedStack is a stack that holds at most a fixed number of elements, such
+// as a recursion limit or an undo history. What happens when it is full is
+// decided by its OverflowPolicy. It is safe for concurrent use.
+//
+// A BoundedStack must be created with NewBoundedStack or BoundedOf.
+type BoundedStack[E any] struct {
+	// stack is the underlying stack.
+	stack Stack[E]
+
+	// size is the number of elements in the stack.
+	size int
+
+	// capacity is the maximum number of elements in the stack.
+	capacity int
+
+	// policy is what the stack does when it is full.
+	policy OverflowPolicy
+
+	// mu is the mutex for the stack.
+	mu sync.Mutex
+
+	// not_full is signaled whenever elements are removed from the stack.
+	not_full *sync.Cond
+}
+
+// newBounded creates a new BoundedStack on top of the given stack.
+//
+// Parameters:
+//   - stack: The underlying stack. Assumed to be non-nil.
+//   - size: The number of elements in the underlying stack.
+//   - capacity: The maximum number of elements in the stack.
+//   - policy: What the stack does when it is full.
+//
+// Returns:
+//   - *BoundedStack[E]: The new stack. Nil if an error occurred.
+//   - error: An error if the capacity or the policy is not valid.
+//
+// Errors:
+//   - common.ErrBadParam: If the capacity is not positive, if the policy is
+//     unknown or if the size exceeds the capacity.
+func newBounded[E any](stack Stack[E], size, capacity int, policy OverflowPolicy) (*BoundedStack[E], error) {
+	if capacity <= 0 {
+		err := common.NewErrBadParam("capacity", "must be positive")
+		return nil, err
+	} else if policy < OverflowError || policy > OverflowDropBottom {
+		err := common.NewErrBadParam("policy", "is not a known overflow policy")
+		return nil, err
+	} else if size > capacity {
+		err := common.NewErrBadParam("stack", "must not hold more elements than the capacity")
+		return nil, err
+	}
+
+	bs := &BoundedStack[E]{
+		stack:    stack,
+		size:     size,
+		capacity: capacity,
+		policy:   policy,
+	}
+
+	bs.not_full = sync.NewCond(&bs.mu)
+
+	return bs, nil
+}
+
+// NewBoundedStack creates a new, empty BoundedStack. Dropping its bottom-most
+// elements takes constant time.
+//
+// Parameters:
+//   - capacity: The maximum number of elements in the stack.
+//   - policy: What the stack does when it is full.
+//
+// Returns:
+//   - *BoundedStack[E]: The new stack. Nil if an error occurred.
+//   - error: An error if the capacity or the policy is not valid.
+//
+// Errors:
+//   - common.ErrBadParam: If the capacity is not positive or if the policy is
+//     unknown.
+func NewBoundedStack[E any](capacity int, policy OverflowPolicy) (*BoundedStack[E], error) {
+	var rs *ringStack[E]
+
+	if capacity > 0 {
+		rs = newRingStack[E](capacity)
+	}
+
+	bs, err := newBounded[E](rs, 0, capacity, policy)
+	return bs, err
+}
+
+// BoundedOf creates a new BoundedStack on top of the given stack, whose
+// elements are kept. The stack must not be used directly afterwards.
+//
+// The OverflowDropBottom policy is only supported by the stacks of this
+// package that can drop their bottom-most elements in place: ArrayStack and
+// UnsyncStack. Any other stack would have to be rebuilt, which cannot be done
+// atomically.
+//
+// Parameters:
+//   - stack: The stack to bound.
+//   - capacity: The maximum number of elements in the stack.
+//   - policy: What the stack does when it is full.
+//
+// Returns:
+//   - *BoundedStack[E]: The new stack. Nil if an error occurred.
+//   - error: An error if the parameters are not valid.
+//
+// Errors:
+//   - common.ErrBadParam: If the stack is nil, if the capacity is not
+//     positive, if the policy is unknown or not supported by the stack or if
+//     the stack holds more elements than the capacity.
+func BoundedOf[E any](stack Stack[E], capacity int, policy OverflowPolicy) (*BoundedStack[E], error) {
+	if stack == nil {
+		err := common.NewErrNilParam("stack")
+		return nil, err
+	}
+
+	if policy == OverflowDropBottom {
+		_, ok := stack.(bottomDropper)
+		if !ok {
+			err := common.NewErrBadParam("policy", "must not be OverflowDropBottom for a stack that cannot drop its bottom-most elements")
+			return nil, err
+		}
+	}
+
+	size := len(stack.Slice())
+
+	bs, err := newBounded(stack, size, capacity, policy)
+	return bs, err
+}
+
+// Cap returns the maximum number of elements in the stack.
+//
+// Returns:
+//   - int: The capacity of the stack. Zero if the receiver is nil.
+func (bs *BoundedStack[E]) Cap() int {
+	if bs == nil {
+		return 0
+	}
+
+	return bs.capacity
+}
+
+// Len returns the number of elements in the stack.
+//
+// Returns:
+//   - int: The number of elements in the stack. Zero if the receiver is nil.
+func (bs *BoundedStack[E]) Len() int {
+	if bs == nil {
+		return 0
+	}
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	return bs.size
+}
+
+// dropBottom removes the n bottom-most elements of the stack. The caller must
+// hold the lock and the underlying stack must implement bottomDropper.
+//
+// Parameters:
+//   - n: The number of elements to remove. Assumed to be at most the size of
+//     the stack.
+//
+// Returns:
+//   - error: An error if the underlying stack failed.
+func (bs *BoundedStack[E]) dropBottom(n int) error {
+	bd := bs.stack.(bottomDropper) // Checked by the constructors.
+
+	err := bd.dropBottom(n)
+	if err != nil {
+		return err
+	}
+
+	bs.size -= n
+
+	return nil
+}
+
+// makeRoom applies the overflow policy until there is room for n more
+// elements. The caller must hold the lock.
+//
+// Parameters:
+//   - n: The number of elements to make room for. Assumed to be at most the
+//     capacity.
+//
+// Returns:
+//   - error: An error if there is no room and the policy does not make any.
+//
+// Errors:
+//   - ErrFullStack: If the policy is OverflowError and there is no room.
+//   - any other error: Returned by the underlying stack.
+func (bs *BoundedStack[E]) makeRoom(n int) error {
+	free := bs.capacity - bs.size
+	if n <= free {
+		return nil
+	}
+
+	switch bs.policy {
+	case OverflowBlock:
+		for bs.capacity-bs.size < n {
+			bs.not_full.Wait()
+		}
+
+		return nil
+	case OverflowDropBottom:
+		err := bs.dropBottom(n - free)
+		return err
+	default:
+		return ErrFullStack
+	}
+}
+
+// Push implements CoreStack. If the stack is full, the overflow policy is
+// applied: the push fails with ErrFullStack, waits for an element to be
+// popped or drops the bottom-most element.
+func (bs *BoundedStack[E]) Push(e E) error {
+	if bs == nil {
+		return common.ErrNilReceiver
+	}
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	err := bs.makeRoom(1)
+	if err != nil {
+		return err
+	}
+
+	err = bs.stack.Push(e)
+	if err != nil {
+		return err
+	}
+
+	bs.size++
+
+	return nil
+}
+
+// Pop implements CoreStack.
+func (bs *BoundedStack[E]) Pop() (E, error) {
+	if bs == nil {
+		return *new(E), common.ErrNilReceiver
+	}
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	e, err := bs.stack.Pop()
+	if err != nil {
+		return *new(E), err
+	}
+
+	bs.size--
+	bs.not_full.Broadcast()
+
+	return e, nil
+}
+
+// IsEmpty implements CoreStack.
+func (bs *BoundedStack[E]) IsEmpty() bool {
+	if bs == nil {
+		return true
+	}
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	ok := bs.size == 0
+	return ok
+}
+
+// Slice implements Collection.
+func (bs *BoundedStack[E]) Slice() []E {
+	if bs == nil {
+		return nil
+	}
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	slice := bs.stack.Slice()
+	return slice
+}
+
+// Reset implements Collection.
+func (bs *BoundedStack[E]) Reset() error {
+	if bs == nil {
+		return common.ErrNilReceiver
+	}
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	err := bs.stack.Reset()
+	if err != nil {
+		return err
+	}
+
+	bs.size = 0
+	bs.not_full.Broadcast()
+
+	return nil
+}
+
+// PushMany pushes all elements in the slice onto the stack in the order they
+// are given in the slice, so that elems[0] ends on top. The elements are
+// pushed at once: no other operation happens in between. If they do not all
+// fit, the overflow policy is applied to the whole slice:
+//   - OverflowError: nothing is pushed and ErrFullStack is returned.
+//   - OverflowBlock: the push waits until there is room for every element.
+//     It fails if there are more elements than the capacity, since they could
+//     never fit.
+//   - OverflowDropBottom: the bottom-most elements are dropped to make room,
+//     as if the elements were pushed one at a time. If there are more
+//     elements than the capacity, only the first Cap() of them are kept.
+//
+// Parameters:
+//   - elems: The elements to push onto the stack.
+//
+// Returns:
+//   - error: An error if the elements could not be pushed onto the stack.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the stack is nil.
+//   - ErrFullStack: If the policy is OverflowError and the elements do not
+//     fit.
+//   - common.ErrBadParam: If the policy is OverflowBlock and there are more
+//     elements than the capacity.
+//   - any other error: Returned by the underlying stack.
+func (bs *BoundedStack[E]) PushMany(elems []E) error {
+	if bs == nil {
+		return common.ErrNilReceiver
+	} else if len(elems) == 0 {
+		return nil
+	}
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	if len(elems) > bs.capacity {
+		switch bs.policy {
+		case OverflowBlock:
+			err := common.NewErrBadParam("elems", "must not have more elements than the capacity")
+			return err
+		case OverflowDropBottom:
+			elems = elems[:bs.capacity]
+		default:
+			return ErrFullStack
+		}
+	}
+
+	err := bs.makeRoom(len(elems))
+	if err != nil {
+		return err
+	}
+
+	err = Push(bs.stack, elems)
+	if err != nil {
+		bs.size = len(bs.stack.Slice())
+		return err
+	}
+
+	bs.size += len(elems)
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package stack
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	common "github.com/PlayerR9/mygo-data/common"
+)
+
+// TestBoundedStackError checks that the OverflowError policy rejects the
+// elements that do not fit and leaves the stack unchanged.
+func TestBoundedStackError(t *testing.T) {
+	bs, _ := NewBoundedStack[int](3, OverflowError)
+
+	_ = bs.PushMany([]int{1, 2})
+
+	err := bs.PushMany([]int{3, 4})
+	if err != ErrFullStack {
+		t.Fatalf("PushMany() = %v, want %v", err, ErrFullStack)
+	}
+
+	_ = bs.Push(3)
+
+	err = bs.Push(4)
+	if err != ErrFullStack {
+		t.Fatalf("Push() = %v, want %v", err, ErrFullStack)
+	}
+
+	if got, want := bs.Slice(), []int{3, 1, 2}; !slices.Equal(got, want) {
+		t.Fatalf("Slice() = %v, want %v", got, want)
+	}
+
+	if bs.Len() != 3 {
+		t.Fatalf("Len() = %d, want 3", bs.Len())
+	}
+}
+
+// TestBoundedStackBlock checks that the OverflowBlock policy waits for
+// elements to be popped and rejects batches that could never fit.
+func TestBoundedStackBlock(t *testing.T) {
+	bs, _ := NewBoundedStack[int](2, OverflowBlock)
+
+	_ = bs.PushMany([]int{1, 2})
+
+	done := make(chan error)
+
+	go func() {
+		done <- bs.PushMany([]int{3, 4})
+	}()
+
+	select {
+	case err := <-done:
+		t.Fatalf("PushMany() on a full stack returned %v without waiting", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	_, _ = bs.Pop()
+
+	select {
+	case err := <-done:
+		t.Fatalf("PushMany() returned %v with room for one element only", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	_, _ = bs.Pop()
+
+	err := <-done
+	if err != nil {
+		t.Fatalf("PushMany() = %v, want nil", err)
+	}
+
+	if got, want := bs.Slice(), []int{3, 4}; !slices.Equal(got, want) {
+		t.Fatalf("Slice() = %v, want %v", got, want)
+	}
+
+	var bad *common.ErrBadParam
+
+	err = bs.PushMany([]int{5, 6, 7})
+	if !errors.As(err, &bad) {
+		t.Fatalf("PushMany() of more elements than the capacity = %v, want a *common.ErrBadParam", err)
+	}
+}
+
+// TestBoundedStackDropBottom checks that the OverflowDropBottom policy drops
+// the bottom-most elements as if the elements were pushed one at a time, on
+// every stack that supports it.
+func TestBoundedStackDropBottom(t *testing.T) {
+	impls := []struct {
+		name string
+		new  func() *BoundedStack[int]
+	}{
+		{"ringStack", func() *BoundedStack[int] {
+			bs, _ := NewBoundedStack[int](4, OverflowDropBottom)
+			return bs
+		}},
+		{"ArrayStack", func() *BoundedStack[int] {
+			bs, _ := BoundedOf[int](new(ArrayStack[int]), 4, OverflowDropBottom)
+			return bs
+		}},
+		{"UnsyncStack", func() *BoundedStack[int] {
+			bs, _ := BoundedOf[int](new(UnsyncStack[int]), 4, OverflowDropBottom)
+			return bs
+		}},
+	}
+
+	for _, impl := range impls {
+		bs := impl.new()
+
+		for i := range 6 {
+			_ = bs.Push(i)
+		}
+
+		if got, want := bs.Slice(), []int{5, 4, 3, 2}; !slices.Equal(got, want) {
+			t.Fatalf("%s: Slice() = %v, want %v", impl.name, got, want)
+		}
+
+		_ = bs.PushMany([]int{6, 7})
+
+		if got, want := bs.Slice(), []int{6, 7, 5, 4}; !slices.Equal(got, want) {
+			t.Fatalf("%s: Slice() = %v after PushMany, want %v", impl.name, got, want)
+		}
+
+		_ = bs.PushMany([]int{8, 9, 10, 11, 12, 13})
+
+		if got, want := bs.Slice(), []int{8, 9, 10, 11}; !slices.Equal(got, want) {
+			t.Fatalf("%s: Slice() = %v after an oversized PushMany, want %v", impl.name, got, want)
+		}
+
+		if bs.Len() != 4 {
+			t.Fatalf("%s: Len() = %d, want 4", impl.name, bs.Len())
+		}
+	}
+}
+
+// TestBoundedOfDropBottomUnsupported checks that the OverflowDropBottom policy
+// is rejected for stacks that cannot drop their bottom-most elements in place.
+func TestBoundedOfDropBottomUnsupported(t *testing.T) {
+	var bad *common.ErrBadParam
+
+	_, err := BoundedOf[int](new(LinkedStack[int]), 4, OverflowDropBottom)
+	if !errors.As(err, &bad) {
+		t.Fatalf("BoundedOf() = %v, want a *common.ErrBadParam", err)
+	}
+
+	_, err = BoundedOf[int](new(LinkedStack[int]), 4, OverflowError)
+	if err != nil {
+		t.Fatalf("BoundedOf() with OverflowError = %v, want nil", err)
+	}
+}
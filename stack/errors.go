@@ -1,6 +1,9 @@
 package stack
 
-import "errors"
+import (
+	"errors"
+	"strconv"
+)
 
 var (
 	// ErrEmptyStack occurs when the stack is empty. This error can be checked
@@ -9,8 +12,106 @@ var (
 	// Format:
 	// 	"stack is empty"
 	ErrEmptyStack error
+
+	// ErrInvalidMark occurs when a mark of a RefusableStack is used after it
+	// was committed or rolled back past, or on another stack. This error can
+	// be checked with the == operator.
+	//
+	// Format:
+	// 	"mark is not active"
+	ErrInvalidMark error
+
+	// ErrFullStack occurs when an element is pushed onto a BoundedStack that
+	// is full. This error can be checked with the == operator.
+	//
+	// Format:
+	// 	"stack is full"
+	ErrFullStack error
+
+	// ErrClosedStack occurs when a BlockingStack is used after it was closed,
+	// or when it is closed while waiting on it. This error can be checked with
+	// the == operator.
+	//
+	// Format:
+	// 	"stack is closed"
+	ErrClosedStack error
 )
 
 func init() {
 	ErrEmptyStack = errors.New("stack is empty")
+	ErrInvalidMark = errors.New("mark is not active")
+	ErrFullStack = errors.New("stack is full")
+	ErrClosedStack = errors.New("stack is closed")
+}
+
+// ErrAborted occurs when an operation of a RefusableStack on its popped
+// elements failed and was undone: the RefusableStack is left as it was
+// before the operation, unless Restored is positive.
+type ErrAborted struct {
+	// Op is the name of the operation that failed.
+	Op string
+
+	// Pending is the number of popped elements the operation was applied to.
+	Pending int
+
+	// Restored is the number of popped elements that could not be taken back
+	// from the underlying stack when undoing the operation. They are both on
+	// the stack and among the popped elements. Zero if the operation was fully
+	// undone.
+	Restored int
+
+	// Err is the reason the operation failed.
+	Err error
+}
+
+// Error implements error.
+func (e ErrAborted) Error() string {
+	msg := e.Op + " aborted with " + strconv.Itoa(e.Pending) + " pending elements"
+
+	if e.Restored > 0 {
+		msg += ", " + strconv.Itoa(e.Restored) + " of them left restored"
+	}
+
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+
+	return msg
+}
+
+// Unwrap returns the reason the operation failed.
+//
+// Returns:
+//   - error: The reason the operation failed.
+func (e ErrAborted) Unwrap() error {
+	return e.Err
+}
+
+// NewErrAborted returns an error with the given operation, number of pending
+// elements and reason.
+//
+// Parameters:
+//   - op: The name of the operation that failed.
+//   - pending: The number of popped elements the operation was applied to.
+//   - err: The reason the operation failed.
+//
+// Returns:
+//   - error: An instance of ErrAborted. Never returns nil.
+//
+// Format:
+//
+//	"<op> aborted with <pending> pending elements: <err>"
+//
+// Where:
+//   - <op> is the name of the operation that failed.
+//   - <pending> is the number of popped elements the operation was applied to.
+//   - : <err> is the reason the operation failed. It is omitted if nil.
+func NewErrAborted(op string, pending int, err error) error {
+	e := &ErrAborted{
+		Op:      op,
+		Pending: pending,
+		Err:     err,
+	}
+
+	return e
 }
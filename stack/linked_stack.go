@@ -0,0 +1,248 @@
+package stack
+
+import (
+	"sync"
+
+	common "github.com/PlayerR9/mygo-data/common"
+)
+
+// linkedNode is a node of a LinkedStack.
+type linkedNode[E any] struct {
+	// elem is the element of the node.
+	elem E
+
+	// next is the node below this one.
+	next *linkedNode[E]
+}
+
+// LinkedStack is a generic stack implemented using a singly linked list. It
+// never copies its elements when it grows and releases the memory of popped
+// elements right away.
+//
+// Popped nodes can be recycled by later pushes to reduce allocations; see
+// NewLinkedStack. An empty stack without pooling can be created with the
+// `ls := new(LinkedStack[E])` constructor.
+type LinkedStack[E any] struct {
+	// top is the node on top of the stack.
+	top *linkedNode[E]
+
+	// size is the number of elements in the stack.
+	size int
+
+	// free is the list of recycled nodes.
+	free *linkedNode[E]
+
+	// free_size is the number of recycled nodes.
+	free_size int
+
+	// pool_size is the maximum number of recycled nodes.
+	pool_size int
+
+	// mu is the mutex for the stack.
+	mu sync.RWMutex
+}
+
+// NewLinkedStack creates a new, empty LinkedStack.
+//
+// Parameters:
+//   - pool_size: The maximum number of popped nodes kept to be reused by later
+//     pushes. If zero or negative, nodes are not recycled.
+//
+// Returns:
+//   - *LinkedStack[E]: The new stack. Never returns nil.
+func NewLinkedStack[E any](pool_size int) *LinkedStack[E] {
+	ls := &LinkedStack[E]{
+		pool_size: max(pool_size, 0),
+	}
+
+	return ls
+}
+
+// newNode returns a node holding the element, recycled if possible. The
+// caller must hold the lock.
+//
+// Parameters:
+//   - e: The element of the node.
+//   - next: The node below the new node.
+//
+// Returns:
+//   - *linkedNode[E]: The node. Never returns nil.
+func (ls *LinkedStack[E]) newNode(e E, next *linkedNode[E]) *linkedNode[E] {
+	n := ls.free
+
+	if n == nil {
+		n = new(linkedNode[E])
+	} else {
+		ls.free = n.next
+		ls.free_size--
+	}
+
+	n.elem = e
+	n.next = next
+
+	return n
+}
+
+// recycle keeps the node for later pushes if the pool is not full. The caller
+// must hold the lock.
+//
+// Parameters:
+//   - n: The node to recycle. It must not be referenced anymore.
+func (ls *LinkedStack[E]) recycle(n *linkedNode[E]) {
+	if ls.free_size >= ls.pool_size {
+		return
+	}
+
+	n.elem = *new(E)
+	n.next = ls.free
+
+	ls.free = n
+	ls.free_size++
+}
+
+// Push implements CoreStack.
+func (ls *LinkedStack[E]) Push(e E) error {
+	if ls == nil {
+		return common.ErrNilReceiver
+	}
+
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	ls.top = ls.newNode(e, ls.top)
+	ls.size++
+
+	return nil
+}
+
+// Pop implements CoreStack.
+func (ls *LinkedStack[E]) Pop() (E, error) {
+	if ls == nil {
+		return *new(E), common.ErrNilReceiver
+	}
+
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	if ls.top == nil {
+		return *new(E), ErrEmptyStack
+	}
+
+	n := ls.top
+	e := n.elem
+
+	ls.top = n.next
+	ls.size--
+
+	ls.recycle(n)
+
+	return e, nil
+}
+
+// Peek returns the element on top of the stack without removing it.
+//
+// Returns:
+//   - E: The element on top of the stack.
+//   - error: An error if the stack is nil or empty.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - ErrEmptyStack: If the stack is empty.
+func (ls *LinkedStack[E]) Peek() (E, error) {
+	if ls == nil {
+		return *new(E), common.ErrNilReceiver
+	}
+
+	ls.mu.RLock()
+	defer ls.mu.RUnlock()
+
+	if ls.top == nil {
+		return *new(E), ErrEmptyStack
+	}
+
+	return ls.top.elem, nil
+}
+
+// IsEmpty implements CoreStack.
+func (ls *LinkedStack[E]) IsEmpty() bool {
+	if ls == nil {
+		return true
+	}
+
+	ls.mu.RLock()
+	defer ls.mu.RUnlock()
+
+	ok := ls.top == nil
+	return ok
+}
+
+// Slice implements Collection.
+func (ls *LinkedStack[E]) Slice() []E {
+	if ls == nil {
+		return nil
+	}
+
+	ls.mu.RLock()
+	defer ls.mu.RUnlock()
+
+	if ls.size == 0 {
+		return nil
+	}
+
+	slice := make([]E, 0, ls.size)
+
+	for n := ls.top; n != nil; n = n.next {
+		slice = append(slice, n.elem)
+	}
+
+	return slice
+}
+
+// Reset implements Collection. The nodes of the stack are recycled as long as
+// the pool is not full.
+func (ls *LinkedStack[E]) Reset() error {
+	if ls == nil {
+		return common.ErrNilReceiver
+	}
+
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	for n := ls.top; n != nil && ls.free_size < ls.pool_size; {
+		next := n.next
+		ls.recycle(n)
+		n = next
+	}
+
+	ls.top = nil
+	ls.size = 0
+
+	return nil
+}
+
+// PushMany pushes all elements in the slice onto the stack in the order they are given in the slice.
+//
+// Parameters:
+//   - elems: The elements to push onto the stack.
+//
+// Returns:
+//   - error: An error if the elements could not be pushed onto the stack.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the stack is nil.
+func (ls *LinkedStack[E]) PushMany(elems []E) error {
+	if ls == nil {
+		return common.ErrNilReceiver
+	}
+
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	for i := len(elems) - 1; i >= 0; i-- {
+		ls.top = ls.newNode(elems[i], ls.top)
+	}
+
+	ls.size += len(elems)
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package stack
+
+import (
+	"slices"
+	"testing"
+)
+
+// benchStack is a stack used by the benchmarks.
+type benchStack interface {
+	Stack[int]
+
+	// PushMany pushes all elements in the slice onto the stack.
+	PushMany(elems []int) error
+}
+
+// benchStacks are the stack implementations compared by the benchmarks.
+var benchStacks = []struct {
+	name string
+	new  func() benchStack
+}{
+	{"ArrayStack", func() benchStack { return new(ArrayStack[int]) }},
+	{"LinkedStack", func() benchStack { return new(LinkedStack[int]) }},
+	{"LinkedStack/pooled", func() benchStack { return NewLinkedStack[int](1024) }},
+}
+
+// benchWorkloads are the workloads run against every stack implementation.
+var benchWorkloads = []struct {
+	name string
+	run  func(b *testing.B, s benchStack)
+}{
+	{"PushPop", func(b *testing.B, s benchStack) {
+		// Keeps the stack between 0 and 1024 elements, like a parser stack.
+		for range b.N {
+			for i := range 1024 {
+				_ = s.Push(i)
+			}
+
+			for range 1024 {
+				_, _ = s.Pop()
+			}
+		}
+	}},
+	{"Interleaved", func(b *testing.B, s benchStack) {
+		for range b.N {
+			for i := range 1024 {
+				_ = s.Push(i)
+				_ = s.Push(i)
+				_, _ = s.Pop()
+			}
+
+			_ = s.Reset()
+		}
+	}},
+	{"PushMany", func(b *testing.B, s benchStack) {
+		elems := make([]int, 1024)
+
+		for range b.N {
+			_ = s.PushMany(elems)
+			_ = s.Reset()
+		}
+	}},
+	{"Slice", func(b *testing.B, s benchStack) {
+		_ = s.PushMany(make([]int, 1024))
+
+		b.ResetTimer()
+
+		for range b.N {
+			_ = s.Slice()
+		}
+	}},
+}
+
+// BenchmarkStacks runs every workload against every stack implementation.
+func BenchmarkStacks(b *testing.B) {
+	for _, w := range benchWorkloads {
+		for _, impl := range benchStacks {
+			b.Run(w.name+"/"+impl.name, func(b *testing.B) {
+				b.ReportAllocs()
+
+				w.run(b, impl.new())
+			})
+		}
+	}
+}
+
+// TestLinkedStack checks that LinkedStack behaves like ArrayStack, with and
+// without pooling.
+func TestLinkedStack(t *testing.T) {
+	for _, impl := range benchStacks {
+		s := impl.new()
+
+		_ = s.PushMany([]int{3, 4})
+		_ = s.Push(2)
+		_ = s.Push(1)
+
+		if got, want := s.Slice(), []int{1, 2, 3, 4}; !slices.Equal(got, want) {
+			t.Fatalf("%s: Slice() = %v, want %v", impl.name, got, want)
+		}
+
+		top, err := s.Pop()
+		if err != nil || top != 1 {
+			t.Fatalf("%s: Pop() = %d, %v, want 1, nil", impl.name, top, err)
+		}
+
+		_ = s.Reset()
+
+		if !s.IsEmpty() {
+			t.Fatalf("%s: stack is not empty after Reset", impl.name)
+		}
+
+		_, err = s.Pop()
+		if err != ErrEmptyStack {
+			t.Fatalf("%s: Pop() on an empty stack returned %v", impl.name, err)
+		}
+	}
+}
@@ -1,16 +1,26 @@
 package stack
 
 import (
+	"errors"
+
 	common "github.com/PlayerR9/mygo-data/common"
 )
 
-// RefusableStack is a stack that can be reset.
+// RefusableStack is a stack that can be reset. Nested savepoints can be
+// created with Mark and rolled back with RollbackTo.
 type RefusableStack[E any] struct {
 	// stack is the stack that is being reset.
 	stack Stack[E]
 
 	// popped is the stack that is being reset.
 	popped []E
+
+	// marks are the active savepoints, from the outermost to the innermost.
+	marks []savepoint
+
+	// journal records the operations made since the outermost active
+	// savepoint. Empty if there is none.
+	journal []journalEntry[E]
 }
 
 // Push implements CoreStack.
@@ -20,7 +30,13 @@ func (s *RefusableStack[E]) Push(e E) error {
 	}
 
 	err := s.stack.Push(e)
-	return err
+	if err != nil {
+		return err
+	}
+
+	s.record(journalEntry[E]{kind: opPush})
+
+	return nil
 }
 
 // Pop implements CoreStack.
@@ -36,31 +52,203 @@ func (s *RefusableStack[E]) Pop() (E, error) {
 
 	s.popped = append(s.popped, top)
 
+	s.record(journalEntry[E]{kind: opPop, elem: top})
+
 	return top, nil
 }
 
+// Peek returns the element on top of the stack without removing it. It is
+// not recorded as a pop. The underlying stack must have a Peek method, as
+// every stack of this package but BoundedStack does, since popping and pushing
+// back its top element would not be atomic.
+//
+// Returns:
+//   - E: The element on top of the stack.
+//   - error: An error if the stack is nil or empty.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - ErrEmptyStack: If the stack is empty.
+//   - errors.ErrUnsupported: If the underlying stack has no Peek method.
+//   - any other error: Returned by the underlying stack.
+func (s *RefusableStack[E]) Peek() (E, error) {
+	if s == nil {
+		return *new(E), common.ErrNilReceiver
+	}
+
+	v, ok := s.stack.(interface{ Peek() (E, error) })
+	if !ok {
+		return *new(E), errors.ErrUnsupported
+	}
+
+	top, err := v.Peek()
+	return top, err
+}
+
+// Len returns the number of elements in the stack, the popped elements
+// excluded.
+//
+// Returns:
+//   - int: The number of elements in the stack. Zero if the receiver is nil.
+func (s *RefusableStack[E]) Len() int {
+	if s == nil {
+		return 0
+	}
+
+	if v, ok := s.stack.(interface{ Len() int }); ok {
+		return v.Len()
+	}
+
+	return len(s.stack.Slice())
+}
+
+// PopN pops n elements from the stack. Either all of them are popped or the
+// stack is left unchanged. The popped elements can be refused like any other.
+//
+// Parameters:
+//   - n: The number of elements to pop.
+//
+// Returns:
+//   - []E: The popped elements, from the top of the stack downwards. Nil if n
+//     is zero or if an error occurred.
+//   - error: An error if the elements could not be popped.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If n is negative.
+//   - ErrEmptyStack: If the stack has fewer than n elements.
+//   - any other error: Returned by the underlying stack.
+func (s *RefusableStack[E]) PopN(n int) ([]E, error) {
+	if s == nil {
+		return nil, common.ErrNilReceiver
+	}
+
+	elems, err := Pop(s.stack, n)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range elems {
+		s.popped = append(s.popped, e)
+
+		s.record(journalEntry[E]{kind: opPop, elem: e})
+	}
+
+	return elems, nil
+}
+
+// PopWhile pops the elements on top of the stack as long as they satisfy the
+// predicate. The popped elements can be refused like any other. If the
+// underlying stack has a PopWhile method, the elements are checked and popped
+// at once by it; otherwise, they are checked with Peek one at a time.
+//
+// Parameters:
+//   - pred: The predicate the popped elements satisfy.
+//
+// Returns:
+//   - []E: The popped elements, from the top of the stack downwards. Nil if
+//     none was popped.
+//   - error: An error if the elements could not be popped. The elements
+//     popped before the error are still returned.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If the predicate is nil.
+//   - errors.ErrUnsupported: If the underlying stack has neither a PopWhile
+//     nor a Peek method.
+//   - any other error: Returned by the underlying stack.
+func (s *RefusableStack[E]) PopWhile(pred func(e E) bool) ([]E, error) {
+	if s == nil {
+		return nil, common.ErrNilReceiver
+	} else if pred == nil {
+		err := common.NewErrNilParam("pred")
+		return nil, err
+	}
+
+	if v, ok := s.stack.(interface {
+		PopWhile(func(E) bool) ([]E, error)
+	}); ok {
+		elems, err := v.PopWhile(pred)
+
+		for _, e := range elems {
+			s.popped = append(s.popped, e)
+
+			s.record(journalEntry[E]{kind: opPop, elem: e})
+		}
+
+		return elems, err
+	}
+
+	var elems []E
+
+	for {
+		top, err := s.Peek()
+		if err == ErrEmptyStack {
+			return elems, nil
+		} else if err != nil {
+			return elems, err
+		}
+
+		if !pred(top) {
+			return elems, nil
+		}
+
+		top, err = s.Pop()
+		if err != nil {
+			return elems, err
+		}
+
+		elems = append(elems, top)
+	}
+}
+
+// IsEmpty implements CoreStack.
+func (s *RefusableStack[E]) IsEmpty() bool {
+	if s == nil {
+		return true
+	}
+
+	ok := s.stack.IsEmpty()
+	return ok
+}
+
 // Slice implements Collection.
 func (s RefusableStack[E]) Slice() []E {
 	elems := s.stack.Slice()
 	return elems
 }
 
-// Reset implements common.Resetter.
+// Reset implements common.Resetter. If a savepoint is active, the reset can be
+// rolled back like any other operation. The popped elements are only
+// discarded once the underlying stack is reset; if it fails, an *ErrAborted
+// wrapping its error is returned and the popped elements are kept.
 func (s *RefusableStack[E]) Reset() error {
 	if s == nil {
 		return common.ErrNilReceiver
 	}
 
+	var elems []E
+
+	if len(s.marks) > 0 {
+		elems = s.stack.Slice()
+	}
+
 	err := s.stack.Reset()
 	if err != nil {
+		err = NewErrAborted("reset", len(s.popped), err)
 		return err
 	}
 
+	s.record(journalEntry[E]{kind: opReset, elems: elems, popped: s.popped})
+
 	if len(s.popped) == 0 {
 		return nil
 	}
 
-	clear(s.popped)
+	if len(s.marks) == 0 {
+		clear(s.popped)
+	}
+
 	s.popped = nil
 
 	return nil
@@ -92,9 +280,10 @@ func RefusableOf[E any](stack Stack[E]) (*RefusableStack[E], error) {
 }
 
 // Accept resets the popped stack, effectively "accepting" the popped elements.
+// It does not touch the underlying stack and thus cannot fail halfway.
 //
 // Returns:
-//   - error: An error if the receiver is nil or if the popped stack could not be reset.
+//   - error: An error if the receiver is nil.
 //
 // Errors:
 //   - common.ErrNilReceiver: If the receiver is nil.
@@ -107,35 +296,72 @@ func (s *RefusableStack[E]) Accept() error {
 		return nil
 	}
 
-	clear(s.popped)
+	if len(s.marks) == 0 {
+		clear(s.popped)
+	} else {
+		s.record(journalEntry[E]{kind: opAccept, elems: s.popped})
+	}
+
 	s.popped = nil
 
 	return nil
 }
 
-// Refuse transfers all elements from the stack to the popped stack,
-// effectively "refusing" the stack.
+// Refuse pushes the popped elements back onto the stack, effectively
+// "refusing" the pops. Either every popped element is restored or, if the
+// underlying stack fails, the elements already pushed back are popped again
+// and the stack is left unchanged. If popping them again fails too, the
+// elements left on the stack are reported by the Restored field of the
+// *ErrAborted and remain among the popped elements.
 //
 // Returns:
-//   - error: An error if the receiver is nil.
+//   - error: An error if the receiver is nil or if the popped elements could
+//     not be restored.
 //
 // Errors:
 //   - common.ErrNilReceiver: If the receiver is nil.
+//   - *ErrAborted: If the underlying stack failed. It wraps the errors of the
+//     underlying stack.
 func (s *RefusableStack[E]) Refuse() error {
 	if s == nil {
 		return common.ErrNilReceiver
 	}
 
-	for len(s.popped) > 0 {
-		top := s.popped[len(s.popped)-1]
-		s.popped = s.popped[:len(s.popped)-1]
+	pending := len(s.popped)
 
-		err := s.stack.Push(top)
-		if err != nil {
-			break
+	for i := pending - 1; i >= 0; i-- {
+		err := s.stack.Push(s.popped[i])
+		if err == nil {
+			continue
+		}
+
+		restored := pending - 1 - i
+
+		for ; restored > 0; restored-- {
+			_, pop_err := s.stack.Pop()
+			if pop_err != nil {
+				err = errors.Join(err, pop_err)
+				break
+			}
+		}
+
+		aborted := &ErrAborted{
+			Op:       "refuse",
+			Pending:  pending,
+			Restored: restored,
+			Err:      err,
 		}
+
+		return aborted
+	}
+
+	for i := pending - 1; i >= 0; i-- {
+		s.record(journalEntry[E]{kind: opRefuse, elem: s.popped[i]})
 	}
 
+	clear(s.popped)
+	s.popped = nil
+
 	return nil
 }
 
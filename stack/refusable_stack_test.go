@@ -0,0 +1,239 @@
+package stack
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+var (
+	// errPushFailed is the error returned by the Push of a failingStack.
+	errPushFailed = errors.New("push failed")
+
+	// errPopFailed is the error returned by the Pop of a failingStack.
+	errPopFailed = errors.New("pop failed")
+)
+
+// failingStack is a stack whose Push fails once it has succeeded a given
+// number of times, and whose Pop fails on demand.
+type failingStack struct {
+	ArrayStack[int]
+
+	// pushes is the number of pushes left before Push fails.
+	pushes int
+
+	// fail_pops makes Pop fail.
+	fail_pops bool
+}
+
+// Push implements CoreStack.
+func (s *failingStack) Push(e int) error {
+	if s.pushes == 0 {
+		return errPushFailed
+	}
+
+	s.pushes--
+
+	err := s.ArrayStack.Push(e)
+	return err
+}
+
+// Pop implements CoreStack.
+func (s *failingStack) Pop() (int, error) {
+	if s.fail_pops {
+		return 0, errPopFailed
+	}
+
+	top, err := s.ArrayStack.Pop()
+	return top, err
+}
+
+// TestRefuseFailure checks that a Refuse that fails partway leaves the stack
+// and the popped elements unchanged and reports the aborted restore.
+func TestRefuseFailure(t *testing.T) {
+	for allowed := range 4 {
+		fs := &failingStack{pushes: 5}
+		s, _ := RefusableOf[int](fs)
+
+		for i := range 5 {
+			_ = s.Push(i)
+		}
+
+		_, _ = s.PopN(4)
+
+		elems, popped := s.Slice(), s.Popped()
+
+		fs.pushes = allowed
+
+		err := s.Refuse()
+
+		var aborted *ErrAborted
+
+		if !errors.As(err, &aborted) {
+			t.Fatalf("allowed %d: Refuse() = %v, want an *ErrAborted", allowed, err)
+		} else if aborted.Op != "refuse" || aborted.Pending != 4 || aborted.Restored != 0 {
+			t.Fatalf("allowed %d: Refuse() = %v, want a refuse aborted with 4 pending elements", allowed, err)
+		} else if !errors.Is(err, errPushFailed) {
+			t.Fatalf("allowed %d: Refuse() = %v, want it to wrap the push error", allowed, err)
+		}
+
+		if got := s.Slice(); !slices.Equal(got, elems) {
+			t.Fatalf("allowed %d: Slice() = %v after a failed Refuse, want %v", allowed, got, elems)
+		}
+
+		if got := s.Popped(); !slices.Equal(got, popped) {
+			t.Fatalf("allowed %d: Popped() = %v after a failed Refuse, want %v", allowed, got, popped)
+		}
+
+		// Once the stack accepts pushes again, the elements can be refused.
+		fs.pushes = 4
+
+		err = s.Refuse()
+		if err != nil {
+			t.Fatalf("allowed %d: Refuse() = %v, want nil", allowed, err)
+		}
+
+		if got, want := s.Slice(), []int{4, 3, 2, 1, 0}; !slices.Equal(got, want) {
+			t.Fatalf("allowed %d: Slice() = %v after Refuse, want %v", allowed, got, want)
+		}
+	}
+}
+
+// TestRefuseRollbackFailure checks that a Refuse whose rollback fails reports
+// the elements it left on the stack instead of claiming the stack unchanged.
+func TestRefuseRollbackFailure(t *testing.T) {
+	fs := &failingStack{pushes: 5}
+	s, _ := RefusableOf[int](fs)
+
+	for i := range 5 {
+		_ = s.Push(i)
+	}
+
+	_, _ = s.PopN(4)
+
+	// Two elements are pushed back before Push fails, and neither can be
+	// popped again.
+	fs.pushes = 2
+	fs.fail_pops = true
+
+	err := s.Refuse()
+
+	var aborted *ErrAborted
+
+	if !errors.As(err, &aborted) {
+		t.Fatalf("Refuse() = %v, want an *ErrAborted", err)
+	} else if aborted.Pending != 4 || aborted.Restored != 2 {
+		t.Fatalf("Refuse() = %v, want 2 of 4 pending elements left restored", err)
+	} else if !errors.Is(err, errPushFailed) || !errors.Is(err, errPopFailed) {
+		t.Fatalf("Refuse() = %v, want it to wrap the push and pop errors", err)
+	}
+
+	want := "refuse aborted with 4 pending elements, 2 of them left restored: push failed\npop failed"
+	if err.Error() != want {
+		t.Fatalf("Refuse() = %q, want %q", err.Error(), want)
+	}
+
+	if got, want := s.Slice(), []int{2, 1, 0}; !slices.Equal(got, want) {
+		t.Fatalf("Slice() = %v after a partial Refuse, want %v", got, want)
+	}
+
+	if got, want := s.Popped(), []int{1, 2, 3, 4}; !slices.Equal(got, want) {
+		t.Fatalf("Popped() = %v after a partial Refuse, want %v", got, want)
+	}
+}
+
+// TestRefusablePeekStack checks that the elements popped by PopN and PopWhile
+// can be refused.
+func TestRefusablePeekStack(t *testing.T) {
+	rs, _ := RefusableOf[int](new(ArrayStack[int]))
+
+	var s PeekStack[int] = rs
+
+	_ = Push[int](s, []int{1, 2, 3, 4, 5})
+
+	_, _ = s.PopN(2)
+
+	elems, err := s.PopWhile(func(e int) bool { return e < 5 })
+	if err != nil || !slices.Equal(elems, []int{3, 4}) {
+		t.Fatalf("PopWhile() = %v, %v, want [3 4], nil", elems, err)
+	}
+
+	if got := rs.Popped(); !slices.Equal(got, []int{4, 3, 2, 1}) {
+		t.Fatalf("Popped() = %v, want [4 3 2 1]", got)
+	}
+
+	_ = rs.Refuse()
+
+	if got := s.Slice(); !slices.Equal(got, []int{1, 2, 3, 4, 5}) {
+		t.Fatalf("Slice() = %v after Refuse, want [1 2 3 4 5]", got)
+	}
+}
+
+// TestRefusablePeekWrappers checks Peek and PopWhile on a RefusableStack over
+// each stack that has a Peek method but no PopWhile method.
+func TestRefusablePeekWrappers(t *testing.T) {
+	stacks := []struct {
+		name  string
+		stack Stack[int]
+	}{
+		{"LinkedStack", new(LinkedStack[int])},
+		{"UnsyncStack", new(UnsyncStack[int])},
+		{"TreiberStack", new(TreiberStack[int])},
+		{"BlockingStack", new(BlockingStack[int])},
+	}
+
+	for _, tt := range stacks {
+		s, _ := RefusableOf(tt.stack)
+
+		_, err := s.Peek()
+		if err != ErrEmptyStack {
+			t.Fatalf("%s: Peek() on an empty stack = %v, want %v", tt.name, err, ErrEmptyStack)
+		}
+
+		_ = Push[int](s, []int{1, 2, 3, 4, 5})
+
+		top, err := s.Peek()
+		if err != nil || top != 1 || s.Popped() != nil {
+			t.Fatalf("%s: Peek() = %d, %v, want 1, nil and nothing popped", tt.name, top, err)
+		}
+
+		elems, err := s.PopWhile(func(e int) bool { return e < 4 })
+		if err != nil || !slices.Equal(elems, []int{1, 2, 3}) {
+			t.Fatalf("%s: PopWhile() = %v, %v, want [1 2 3], nil", tt.name, elems, err)
+		}
+
+		elems, err = s.PopWhile(func(e int) bool { return true })
+		if err != nil || !slices.Equal(elems, []int{4, 5}) {
+			t.Fatalf("%s: PopWhile() until empty = %v, %v, want [4 5], nil", tt.name, elems, err)
+		}
+
+		_ = s.Refuse()
+
+		if got := s.Slice(); !slices.Equal(got, []int{1, 2, 3, 4, 5}) {
+			t.Fatalf("%s: Slice() = %v after Refuse, want [1 2 3 4 5]", tt.name, got)
+		}
+	}
+}
+
+// TestRefusablePeekUnsupported checks that Peek and PopWhile fail instead of
+// popping and pushing back the top element of a stack without a Peek method.
+func TestRefusablePeekUnsupported(t *testing.T) {
+	bounded, _ := NewBoundedStack[int](4, OverflowError)
+	s, _ := RefusableOf[int](bounded)
+
+	_ = s.Push(1)
+
+	_, err := s.Peek()
+	if err != errors.ErrUnsupported {
+		t.Fatalf("Peek() = %v, want %v", err, errors.ErrUnsupported)
+	}
+
+	_, err = s.PopWhile(func(int) bool { return true })
+	if err != errors.ErrUnsupported {
+		t.Fatalf("PopWhile() = %v, want %v", err, errors.ErrUnsupported)
+	}
+
+	if got := s.Slice(); !slices.Equal(got, []int{1}) || s.Popped() != nil {
+		t.Fatalf("Slice() = %v and Popped() = %v, want [1] and []", got, s.Popped())
+	}
+}
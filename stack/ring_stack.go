@@ -0,0 +1,127 @@
+package stack
+
+import (
+	common "github.com/PlayerR9/mygo-data/common"
+)
+
+// ringStack is a stack of fixed capacity implemented using a ring buffer, so
+// that its bottom-most element can be dropped in constant time. It is not
+// synchronized; BoundedStack locks around it.
+type ringStack[E any] struct {
+	// buf is the ring buffer.
+	buf []E
+
+	// head is the index of the bottom-most element in the buffer.
+	head int
+
+	// size is the number of elements in the stack.
+	size int
+}
+
+// newRingStack creates a new, empty ringStack.
+//
+// Parameters:
+//   - capacity: The capacity of the stack. Assumed to be positive.
+//
+// Returns:
+//   - *ringStack[E]: The new stack. Never returns nil.
+func newRingStack[E any](capacity int) *ringStack[E] {
+	rs := &ringStack[E]{
+		buf: make([]E, capacity),
+	}
+
+	return rs
+}
+
+// Push implements CoreStack.
+func (rs *ringStack[E]) Push(e E) error {
+	if rs == nil {
+		return common.ErrNilReceiver
+	} else if rs.size == len(rs.buf) {
+		return ErrFullStack
+	}
+
+	rs.buf[(rs.head+rs.size)%len(rs.buf)] = e
+	rs.size++
+
+	return nil
+}
+
+// Pop implements CoreStack.
+func (rs *ringStack[E]) Pop() (E, error) {
+	if rs == nil {
+		return *new(E), common.ErrNilReceiver
+	} else if rs.size == 0 {
+		return *new(E), ErrEmptyStack
+	}
+
+	rs.size--
+
+	idx := (rs.head + rs.size) % len(rs.buf)
+
+	e := rs.buf[idx]
+	rs.buf[idx] = *new(E)
+
+	return e, nil
+}
+
+// IsEmpty implements CoreStack.
+func (rs *ringStack[E]) IsEmpty() bool {
+	ok := rs == nil || rs.size == 0
+	return ok
+}
+
+// Slice implements Collection.
+func (rs *ringStack[E]) Slice() []E {
+	if rs == nil || rs.size == 0 {
+		return nil
+	}
+
+	slice := make([]E, 0, rs.size)
+
+	for i := rs.size - 1; i >= 0; i-- {
+		slice = append(slice, rs.buf[(rs.head+i)%len(rs.buf)])
+	}
+
+	return slice
+}
+
+// Reset implements Collection.
+func (rs *ringStack[E]) Reset() error {
+	if rs == nil {
+		return common.ErrNilReceiver
+	}
+
+	clear(rs.buf)
+
+	rs.head = 0
+	rs.size = 0
+
+	return nil
+}
+
+// dropBottom removes the n bottom-most elements of the stack.
+//
+// Parameters:
+//   - n: The number of elements to remove. Assumed to be at most the size of
+//     the stack.
+//
+// Returns:
+//   - error: An error if the receiver is nil.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+func (rs *ringStack[E]) dropBottom(n int) error {
+	if rs == nil {
+		return common.ErrNilReceiver
+	}
+
+	for ; n > 0; n-- {
+		rs.buf[rs.head] = *new(E)
+
+		rs.head = (rs.head + 1) % len(rs.buf)
+		rs.size--
+	}
+
+	return nil
+}
@@ -0,0 +1,235 @@
+package stack
+
+import (
+	"sync/atomic"
+
+	common "github.com/PlayerR9/mygo-data/common"
+)
+
+// Mark is a savepoint of a RefusableStack, returned by Mark.
+type Mark struct {
+	// serial identifies the savepoint among all the savepoints of all stacks.
+	serial uint64
+
+	// depth is the position of the savepoint among the active savepoints.
+	depth int
+}
+
+// mark_serial is the serial number of the last savepoint.
+var mark_serial atomic.Uint64
+
+// savepoint is an active savepoint of a RefusableStack.
+type savepoint struct {
+	// serial identifies the savepoint.
+	serial uint64
+
+	// pos is the length of the journal when the savepoint was created.
+	pos int
+}
+
+// opKind is the kind of a journaled operation.
+type opKind int
+
+const (
+	// opPush is a push.
+	opPush opKind = iota
+
+	// opPop is a pop; the element was appended to the popped elements.
+	opPop
+
+	// opRefuse is a push of a popped element by Refuse; the element was
+	// removed from the popped elements.
+	opRefuse
+
+	// opAccept is an Accept that cleared the popped elements.
+	opAccept
+
+	// opReset is a Reset.
+	opReset
+)
+
+// journalEntry is an operation recorded while a savepoint is active.
+type journalEntry[E any] struct {
+	// kind is the kind of the operation.
+	kind opKind
+
+	// elem is the popped or refused element, for opPop and opRefuse.
+	elem E
+
+	// elems are the accepted elements for opAccept and the elements of the
+	// stack, from top to bottom, for opReset.
+	elems []E
+
+	// popped are the popped elements before the operation, for opReset.
+	popped []E
+}
+
+// record appends the operation to the journal if a savepoint is active.
+//
+// Parameters:
+//   - entry: The operation.
+func (s *RefusableStack[E]) record(entry journalEntry[E]) {
+	if len(s.marks) == 0 {
+		return
+	}
+
+	s.journal = append(s.journal, entry)
+}
+
+// Mark creates a savepoint. RollbackTo undoes every operation made after it:
+// pushes, pops, Accept, Refuse and Reset. Savepoints nest: a savepoint created
+// after another one is inner to it.
+//
+// Returns:
+//   - Mark: The savepoint. The zero value if the receiver is nil.
+func (s *RefusableStack[E]) Mark() Mark {
+	if s == nil {
+		return Mark{}
+	}
+
+	sp := savepoint{
+		serial: mark_serial.Add(1),
+		pos:    len(s.journal),
+	}
+
+	s.marks = append(s.marks, sp)
+
+	m := Mark{
+		serial: sp.serial,
+		depth:  len(s.marks) - 1,
+	}
+
+	return m
+}
+
+// active checks whether the mark is an active savepoint of the stack.
+//
+// Parameters:
+//   - m: The mark to check.
+//
+// Returns:
+//   - bool: True if the mark is active, false otherwise.
+func (s *RefusableStack[E]) active(m Mark) bool {
+	ok := m.depth >= 0 && m.depth < len(s.marks) && s.marks[m.depth].serial == m.serial
+	return ok
+}
+
+// RollbackTo undoes every operation made after the savepoint, so that the
+// stack and its popped elements are exactly as they were when the savepoint
+// was created. The savepoint stays active and the savepoints inner to it are
+// discarded.
+//
+// Parameters:
+//   - m: The savepoint to roll back to.
+//
+// Returns:
+//   - error: An error if the savepoint is not active or if the underlying stack
+//     failed. In the latter case, the operations that were not undone yet
+//     stay recorded so that the rollback can be retried.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - ErrInvalidMark: If the savepoint is not active.
+//   - any other error: Returned by the underlying stack.
+func (s *RefusableStack[E]) RollbackTo(m Mark) error {
+	if s == nil {
+		return common.ErrNilReceiver
+	} else if !s.active(m) {
+		return ErrInvalidMark
+	}
+
+	s.marks = s.marks[:m.depth+1]
+
+	pos := s.marks[m.depth].pos
+
+	for len(s.journal) > pos {
+		entry := s.journal[len(s.journal)-1]
+
+		err := s.undo(entry)
+		if err != nil {
+			return err
+		}
+
+		s.journal[len(s.journal)-1] = journalEntry[E]{}
+		s.journal = s.journal[:len(s.journal)-1]
+	}
+
+	return nil
+}
+
+// undo undoes a journaled operation.
+//
+// Parameters:
+//   - entry: The operation to undo.
+//
+// Returns:
+//   - error: An error if the underlying stack failed.
+func (s *RefusableStack[E]) undo(entry journalEntry[E]) error {
+	switch entry.kind {
+	case opPush:
+		_, err := s.stack.Pop()
+		return err
+	case opPop:
+		err := s.stack.Push(entry.elem)
+		if err != nil {
+			return err
+		}
+
+		s.popped = s.popped[:len(s.popped)-1]
+	case opRefuse:
+		_, err := s.stack.Pop()
+		if err != nil {
+			return err
+		}
+
+		s.popped = append(s.popped, entry.elem)
+	case opAccept:
+		s.popped = entry.elems
+	case opReset:
+		err := s.stack.Reset()
+		if err != nil {
+			return err
+		}
+
+		for i := len(entry.elems) - 1; i >= 0; i-- {
+			err := s.stack.Push(entry.elems[i])
+			if err != nil {
+				return err
+			}
+		}
+
+		s.popped = entry.popped
+	}
+
+	return nil
+}
+
+// Commit releases the savepoint and the savepoints inner to it, keeping every
+// operation made after it. The operations can still be rolled back by an
+// outer savepoint.
+//
+// Parameters:
+//   - m: The savepoint to release.
+//
+// Returns:
+//   - error: An error if the savepoint is not active.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - ErrInvalidMark: If the savepoint is not active.
+func (s *RefusableStack[E]) Commit(m Mark) error {
+	if s == nil {
+		return common.ErrNilReceiver
+	} else if !s.active(m) {
+		return ErrInvalidMark
+	}
+
+	s.marks = s.marks[:m.depth]
+
+	if len(s.marks) == 0 {
+		clear(s.journal)
+		s.journal = nil
+	}
+
+	return nil
+}
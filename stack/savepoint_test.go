@@ -0,0 +1,118 @@
+package stack
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// TestRollbackToProperty interleaves random operations with nested
+// savepoints and checks that RollbackTo restores exactly the elements and the
+// popped elements the stack had when the savepoint was created.
+func TestRollbackToProperty(t *testing.T) {
+	type snapshot struct {
+		mark   Mark
+		elems  []int
+		popped []int
+	}
+
+	rng := rand.New(rand.NewPCG(21, 21))
+
+	for run := range 300 {
+		s, _ := RefusableOf[int](new(ArrayStack[int]))
+
+		var marks []snapshot
+
+		next := 0
+
+		for step := range 200 {
+			switch op := rng.IntN(10); {
+			case op < 3:
+				err := s.Push(next)
+				if err != nil {
+					t.Fatalf("run %d, step %d: Push returned %v", run, step, err)
+				}
+
+				next++
+			case op < 5:
+				_, _ = s.Pop()
+			case op == 5:
+				switch rng.IntN(3) {
+				case 0:
+					_ = s.Refuse()
+				case 1:
+					_ = s.Accept()
+				case 2:
+					_ = s.Reset()
+				}
+			case op == 6 || op == 7:
+				marks = append(marks, snapshot{
+					mark:   s.Mark(),
+					elems:  s.Slice(),
+					popped: s.Popped(),
+				})
+			case op == 8 && len(marks) > 0:
+				i := rng.IntN(len(marks))
+
+				err := s.RollbackTo(marks[i].mark)
+				if err != nil {
+					t.Fatalf("run %d, step %d: RollbackTo returned %v", run, step, err)
+				}
+
+				if got := s.Slice(); !slices.Equal(got, marks[i].elems) {
+					t.Fatalf("run %d, step %d: Slice() = %v after RollbackTo, want %v", run, step, got, marks[i].elems)
+				}
+
+				if got := s.Popped(); !slices.Equal(got, marks[i].popped) {
+					t.Fatalf("run %d, step %d: Popped() = %v after RollbackTo, want %v", run, step, got, marks[i].popped)
+				}
+
+				// The savepoint stays active; the inner ones are released.
+				marks = marks[:i+1]
+			case op == 9 && len(marks) > 0:
+				i := rng.IntN(len(marks))
+
+				err := s.Commit(marks[i].mark)
+				if err != nil {
+					t.Fatalf("run %d, step %d: Commit returned %v", run, step, err)
+				}
+
+				for _, m := range marks[i+1:] {
+					if s.RollbackTo(m.mark) != ErrInvalidMark {
+						t.Fatalf("run %d, step %d: an inner savepoint is still active after Commit", run, step)
+					}
+				}
+
+				marks = marks[:i]
+			}
+		}
+	}
+}
+
+// TestSavepointInvalidMark checks that released or foreign savepoints are
+// rejected.
+func TestSavepointInvalidMark(t *testing.T) {
+	s, _ := RefusableOf[int](new(ArrayStack[int]))
+	other, _ := RefusableOf[int](new(ArrayStack[int]))
+
+	m := s.Mark()
+
+	if other.RollbackTo(m) != ErrInvalidMark {
+		t.Fatal("RollbackTo accepted a savepoint of another stack")
+	}
+
+	_ = s.Push(1)
+
+	err := s.Commit(m)
+	if err != nil {
+		t.Fatalf("Commit returned %v", err)
+	}
+
+	if s.RollbackTo(m) != ErrInvalidMark {
+		t.Fatal("RollbackTo accepted a committed savepoint")
+	}
+
+	if got := s.Slice(); !slices.Equal(got, []int{1}) {
+		t.Fatalf("Slice() = %v after Commit, want [1]", got)
+	}
+}
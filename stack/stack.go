@@ -1,6 +1,10 @@
 package stack
 
-import common "github.com/PlayerR9/mygo-data/common"
+import (
+	"errors"
+
+	common "github.com/PlayerR9/mygo-data/common"
+)
 
 // CoreStack is a generic stack interface.
 type CoreStack[E any] interface {
@@ -67,8 +71,119 @@ func Push[E any](stack CoreStack[E], elems []E) error {
 	return nil
 }
 
+// Pop pops n elements from the stack. Either all of them are popped or the
+// stack is left unchanged: stacks that do not provide a PopN method are popped
+// one element at a time and the elements already popped are pushed back if a
+// pop fails. If pushing them back fails too, the stack may have been changed
+// and both errors are returned, joined.
+//
+// Parameters:
+//   - stack: The stack to pop the elements from.
+//   - n: The number of elements to pop.
+//
+// Returns:
+//   - []E: The popped elements, in the order they were popped, so that pushing
+//     them back with Push restores the stack. Nil if n is zero or if an error
+//     occurred.
+//   - error: An error if the stack is nil, if n is negative or if the elements
+//     could not be popped.
+//
+// Errors:
+//   - common.ErrNilParam: If the stack is nil.
+//   - common.ErrBadParam: If n is negative.
+//   - ErrEmptyStack: If the stack has fewer than n elements.
+//   - any other error: Implementation-specific. It is joined with the error
+//     of the stack if the popped elements could not be pushed back.
+func Pop[E any](stack CoreStack[E], n int) ([]E, error) {
+	if stack == nil {
+		return nil, common.NewErrNilParam("stack")
+	} else if n < 0 {
+		return nil, common.NewErrBadParam("n", "must not be negative")
+	} else if n == 0 {
+		return nil, nil
+	}
+
+	if v, ok := stack.(interface{ PopN(int) ([]E, error) }); ok {
+		return v.PopN(n)
+	}
+
+	elems := make([]E, 0, n)
+
+	for len(elems) < n {
+		e, err := stack.Pop()
+		if err != nil {
+			restore_err := Push(stack, elems)
+			if restore_err != nil {
+				err = errors.Join(err, restore_err)
+			}
+
+			return nil, err
+		}
+
+		elems = append(elems, e)
+	}
+
+	return elems, nil
+}
+
 // Stack is an interface that extends CoreStack and Collection.
 type Stack[E any] interface {
 	CoreStack[E]
 	Collection[E]
 }
+
+// PeekStack is a Stack that can also look at its top element and pop several
+// elements at once. It is implemented by ArrayStack and RefusableStack, the
+// latter only fully when the stack it wraps has a Peek method, as every stack
+// of this package but BoundedStack does.
+type PeekStack[E any] interface {
+	Stack[E]
+
+	// Peek returns the element on top of the stack without removing it.
+	//
+	// Returns:
+	//   - E: The element on top of the stack.
+	//   - error: An error if the element could not be read.
+	//
+	// Errors:
+	//   - common.ErrNilReceiver: If the receiver is nil.
+	//   - ErrEmptyStack: If the stack is empty.
+	Peek() (E, error)
+
+	// Len returns the number of elements in the stack.
+	//
+	// Returns:
+	//   - int: The number of elements in the stack.
+	Len() int
+
+	// PopN pops n elements from the stack. Either all of them are popped or
+	// the stack is left unchanged.
+	//
+	// Parameters:
+	//   - n: The number of elements to pop.
+	//
+	// Returns:
+	//   - []E: The popped elements, from the top of the stack downwards.
+	//   - error: An error if the elements could not be popped.
+	//
+	// Errors:
+	//   - common.ErrNilReceiver: If the receiver is nil.
+	//   - common.ErrBadParam: If n is negative.
+	//   - ErrEmptyStack: If the stack has fewer than n elements.
+	PopN(n int) ([]E, error)
+
+	// PopWhile pops the elements on top of the stack as long as they satisfy
+	// the predicate.
+	//
+	// Parameters:
+	//   - pred: The predicate the popped elements satisfy.
+	//
+	// Returns:
+	//   - []E: The popped elements, from the top of the stack downwards.
+	//   - error: An error if the elements could not be popped.
+	//
+	// Errors:
+	//   - common.ErrNilReceiver: If the receiver is nil.
+	//   - common.ErrBadParam: If the predicate is nil.
+	PopWhile(pred func(e E) bool) ([]E, error)
+}
@@ -0,0 +1,81 @@
+package stack
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// brokenStack is a stack without a PopN method whose Pop fails once it has
+// succeeded a given number of times, and whose Push fails on demand.
+type brokenStack struct {
+	LinkedStack[int]
+
+	// pops is the number of pops left before Pop fails.
+	pops int
+
+	// fail_pushes makes Push fail.
+	fail_pushes bool
+}
+
+// Push implements CoreStack.
+func (s *brokenStack) Push(e int) error {
+	if s.fail_pushes {
+		return errPushFailed
+	}
+
+	err := s.LinkedStack.Push(e)
+	return err
+}
+
+// PushMany pushes the elements like LinkedStack.PushMany, unless Push fails.
+func (s *brokenStack) PushMany(elems []int) error {
+	if s.fail_pushes {
+		return errPushFailed
+	}
+
+	err := s.LinkedStack.PushMany(elems)
+	return err
+}
+
+// Pop implements CoreStack.
+func (s *brokenStack) Pop() (int, error) {
+	if s.pops == 0 {
+		return 0, errPopFailed
+	}
+
+	s.pops--
+
+	top, err := s.LinkedStack.Pop()
+	return top, err
+}
+
+// TestPopRestore checks that Pop pushes the popped elements back when a pop
+// fails, and reports both errors when they cannot be pushed back.
+func TestPopRestore(t *testing.T) {
+	bs := &brokenStack{pops: 2}
+
+	_ = Push[int](bs, []int{1, 2, 3, 4})
+
+	elems, err := Pop[int](bs, 3)
+	if elems != nil || err != errPopFailed {
+		t.Fatalf("Pop() = %v, %v, want nil, %v", elems, err, errPopFailed)
+	}
+
+	if got := bs.Slice(); !slices.Equal(got, []int{1, 2, 3, 4}) {
+		t.Fatalf("Slice() = %v after a failed Pop, want [1 2 3 4]", got)
+	}
+
+	bs.pops = 2
+	bs.fail_pushes = true
+
+	elems, err = Pop[int](bs, 3)
+	if elems != nil || !errors.Is(err, errPopFailed) || !errors.Is(err, errPushFailed) {
+		t.Fatalf("Pop() = %v, %v, want nil and the pop and push errors", elems, err)
+	}
+
+	// The two popped elements are lost.
+	if got := bs.Slice(); !slices.Equal(got, []int{3, 4}) {
+		t.Fatalf("Slice() = %v after a failed restore, want [3 4]", got)
+	}
+}
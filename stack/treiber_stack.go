@@ -0,0 +1,165 @@
+package stack
+
+import (
+	"sync/atomic"
+
+	common "github.com/PlayerR9/mygo-data/common"
+)
+
+// treiberNode is a node of a TreiberStack. Nodes are never modified once they
+// are published.
+type treiberNode[E any] struct {
+	// elem is the element of the node.
+	elem E
+
+	// next is the node below this one.
+	next *treiberNode[E]
+}
+
+// TreiberStack is a generic lock-free stack (Treiber's algorithm): the top of
+// the stack is an atomic pointer updated with compare-and-swap loops, so that
+// goroutines never block each other. It is safe for concurrent use and suited
+// to highly contended stacks, such as the work queues of a worker pool.
+//
+// An empty stack can be created with the `ts := new(TreiberStack[E])`
+// constructor.
+type TreiberStack[E any] struct {
+	// top is the node on top of the stack.
+	top atomic.Pointer[treiberNode[E]]
+}
+
+// Push implements CoreStack.
+func (ts *TreiberStack[E]) Push(e E) error {
+	if ts == nil {
+		return common.ErrNilReceiver
+	}
+
+	n := &treiberNode[E]{
+		elem: e,
+	}
+
+	for {
+		n.next = ts.top.Load()
+
+		if ts.top.CompareAndSwap(n.next, n) {
+			return nil
+		}
+	}
+}
+
+// Pop implements CoreStack.
+func (ts *TreiberStack[E]) Pop() (E, error) {
+	if ts == nil {
+		return *new(E), common.ErrNilReceiver
+	}
+
+	for {
+		top := ts.top.Load()
+		if top == nil {
+			return *new(E), ErrEmptyStack
+		}
+
+		if ts.top.CompareAndSwap(top, top.next) {
+			return top.elem, nil
+		}
+	}
+}
+
+// Peek returns the element on top of the stack without removing it. Under
+// concurrent use, the element may already have been popped when Peek returns.
+//
+// Returns:
+//   - E: The element on top of the stack.
+//   - error: An error if the stack is nil or empty.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - ErrEmptyStack: If the stack is empty.
+func (ts *TreiberStack[E]) Peek() (E, error) {
+	if ts == nil {
+		return *new(E), common.ErrNilReceiver
+	}
+
+	top := ts.top.Load()
+	if top == nil {
+		return *new(E), ErrEmptyStack
+	}
+
+	return top.elem, nil
+}
+
+// IsEmpty implements CoreStack.
+func (ts *TreiberStack[E]) IsEmpty() bool {
+	ok := ts == nil || ts.top.Load() == nil
+	return ok
+}
+
+// Slice implements Collection. The slice is a consistent snapshot of the
+// stack at some point during the call.
+func (ts *TreiberStack[E]) Slice() []E {
+	if ts == nil {
+		return nil
+	}
+
+	var slice []E
+
+	for n := ts.top.Load(); n != nil; n = n.next {
+		slice = append(slice, n.elem)
+	}
+
+	return slice
+}
+
+// Reset implements Collection.
+func (ts *TreiberStack[E]) Reset() error {
+	if ts == nil {
+		return common.ErrNilReceiver
+	}
+
+	ts.top.Store(nil)
+
+	return nil
+}
+
+// PushMany pushes all elements in the slice onto the stack in the order they are given in the slice.
+// The elements are pushed atomically: concurrent operations see either none
+// or all of them.
+//
+// Parameters:
+//   - elems: The elements to push onto the stack.
+//
+// Returns:
+//   - error: An error if the elements could not be pushed onto the stack.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the stack is nil.
+func (ts *TreiberStack[E]) PushMany(elems []E) error {
+	if ts == nil {
+		return common.ErrNilReceiver
+	} else if len(elems) == 0 {
+		return nil
+	}
+
+	first := &treiberNode[E]{
+		elem: elems[0],
+	}
+
+	last := first
+
+	for _, e := range elems[1:] {
+		n := &treiberNode[E]{
+			elem: e,
+		}
+
+		last.next = n
+		last = n
+	}
+
+	for {
+		last.next = ts.top.Load()
+
+		if ts.top.CompareAndSwap(last.next, first) {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package stack
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+// These tests are meant to be run with the race detector:
+//
+//	go test -race ./stack
+
+// TestTreiberStackConcurrent checks that every element pushed by concurrent
+// producers, one at a time or in batches, is popped exactly once by
+// concurrent consumers.
+func TestTreiberStackConcurrent(t *testing.T) {
+	const (
+		producers = 8
+		consumers = 8
+		per_prod  = 2000
+		batch     = 10
+	)
+
+	ts := new(TreiberStack[int])
+
+	var wg sync.WaitGroup
+
+	for p := range producers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			base := p * per_prod
+
+			for i := 0; i < per_prod; {
+				if i%(3*batch) == 0 && i+batch <= per_prod {
+					elems := make([]int, batch)
+					for j := range elems {
+						elems[j] = base + i + j
+					}
+
+					_ = ts.PushMany(elems)
+
+					i += batch
+				} else {
+					_ = ts.Push(base + i)
+
+					i++
+				}
+			}
+		}()
+	}
+
+	seen := make([][]int, consumers)
+	done := make(chan struct{})
+
+	var cwg sync.WaitGroup
+
+	for c := range consumers {
+		cwg.Add(1)
+
+		go func() {
+			defer cwg.Done()
+
+			for {
+				e, err := ts.Pop()
+				if err == nil {
+					seen[c] = append(seen[c], e)
+					continue
+				}
+
+				select {
+				case <-done:
+					// The producers are done: drain what is left and stop.
+					for {
+						e, err := ts.Pop()
+						if err != nil {
+							return
+						}
+
+						seen[c] = append(seen[c], e)
+					}
+				default:
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(done)
+	cwg.Wait()
+
+	var all []int
+
+	for _, s := range seen {
+		all = append(all, s...)
+	}
+
+	slices.Sort(all)
+
+	if len(all) != producers*per_prod {
+		t.Fatalf("popped %d elements, want %d", len(all), producers*per_prod)
+	}
+
+	for i, e := range all {
+		if e != i {
+			t.Fatalf("sorted popped elements have %d at index %d: an element was lost or duplicated", e, i)
+		}
+	}
+
+	if !ts.IsEmpty() {
+		t.Fatal("stack is not empty")
+	}
+}
+
+// TestTreiberStackPushManyAtomic checks that the batches pushed concurrently
+// by PushMany are never interleaved and keep their order.
+func TestTreiberStackPushManyAtomic(t *testing.T) {
+	const (
+		writers = 8
+		batches = 200
+		batch   = 5
+	)
+
+	ts := new(TreiberStack[int])
+
+	var wg sync.WaitGroup
+
+	for w := range writers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for k := range batches {
+				base := (w*batches + k) * batch
+
+				_ = ts.PushMany([]int{base, base + 1, base + 2, base + 3, base + 4})
+			}
+		}()
+	}
+
+	// Concurrent readers must only ever see whole batches.
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		for range 100 {
+			if len(ts.Slice())%batch != 0 {
+				t.Error("Slice() saw a partial batch")
+				return
+			}
+		}
+	}()
+
+	wg.Wait()
+
+	elems := ts.Slice()
+
+	if len(elems) != writers*batches*batch {
+		t.Fatalf("stack has %d elements, want %d", len(elems), writers*batches*batch)
+	}
+
+	for i := 0; i < len(elems); i += batch {
+		base := elems[i]
+
+		if base%batch != 0 {
+			t.Fatalf("batch at %d starts with %d", i, base)
+		}
+
+		for j := range batch {
+			if elems[i+j] != base+j {
+				t.Fatalf("batch at %d is %v", i, elems[i:i+batch])
+			}
+		}
+	}
+}
+
+// TestUnsyncStack checks the basic operations of UnsyncStack.
+func TestUnsyncStack(t *testing.T) {
+	us := new(UnsyncStack[int])
+
+	_ = us.PushMany([]int{2, 3})
+	_ = us.Push(1)
+
+	if got, want := us.Slice(), []int{1, 2, 3}; !slices.Equal(got, want) {
+		t.Fatalf("Slice() = %v, want %v", got, want)
+	}
+
+	for want := 1; want <= 3; want++ {
+		e, err := us.Pop()
+		if err != nil || e != want {
+			t.Fatalf("Pop() = %d, %v, want %d, nil", e, err, want)
+		}
+	}
+
+	_, err := us.Pop()
+	if err != ErrEmptyStack {
+		t.Fatalf("Pop() on an empty stack returned %v", err)
+	}
+}
+
+// benchPushPop pushes and pops a few elements on the stack.
+//
+// Parameters:
+//   - s: The stack.
+func benchPushPop(s CoreStack[int]) {
+	for i := range 4 {
+		_ = s.Push(i)
+	}
+
+	for range 4 {
+		_, _ = s.Pop()
+	}
+}
+
+// BenchmarkContended compares the stacks under contention: every goroutine
+// of b.RunParallel pushes and pops on the same stack. UnsyncStack cannot be
+// shared, so each goroutine uses its own; it is the uncontended baseline.
+func BenchmarkContended(b *testing.B) {
+	b.Run("TreiberStack", func(b *testing.B) {
+		ts := new(TreiberStack[int])
+
+		b.ReportAllocs()
+
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				benchPushPop(ts)
+			}
+		})
+	})
+
+	b.Run("ArrayStack", func(b *testing.B) {
+		as := new(ArrayStack[int])
+
+		b.ReportAllocs()
+
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				benchPushPop(as)
+			}
+		})
+	})
+
+	b.Run("UnsyncStack", func(b *testing.B) {
+		b.ReportAllocs()
+
+		b.RunParallel(func(pb *testing.PB) {
+			us := new(UnsyncStack[int])
+
+			for pb.Next() {
+				benchPushPop(us)
+			}
+		})
+	})
+}
+
+// BenchmarkContendedPushMany compares PushMany followed by pops under
+// contention.
+func BenchmarkContendedPushMany(b *testing.B) {
+	elems := []int{1, 2, 3, 4, 5, 6, 7, 8}
+
+	impls := []struct {
+		name   string
+		shared bool
+		new    func() benchStack
+	}{
+		{"TreiberStack", true, func() benchStack { return new(TreiberStack[int]) }},
+		{"ArrayStack", true, func() benchStack { return new(ArrayStack[int]) }},
+		{"UnsyncStack", false, func() benchStack { return new(UnsyncStack[int]) }},
+	}
+
+	for _, impl := range impls {
+		b.Run(impl.name, func(b *testing.B) {
+			shared := impl.new()
+
+			b.ReportAllocs()
+
+			b.RunParallel(func(pb *testing.PB) {
+				s := shared
+				if !impl.shared {
+					s = impl.new()
+				}
+
+				for pb.Next() {
+					_ = s.PushMany(elems)
+
+					for range elems {
+						_, _ = s.Pop()
+					}
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package stack
+
+import (
+	common "github.com/PlayerR9/mygo-data/common"
+	"github.com/PlayerR9/mygo-data/stack/internal"
+)
+
+// UnsyncStack is a generic stack implemented using an array, like ArrayStack,
+// but without any synchronization. It is meant for stacks owned by a single
+// goroutine, such as the stack of a parser, where locking is pure overhead.
+// It must not be used concurrently.
+//
+// An empty stack can be created with the `us := new(UnsyncStack[E])`
+// constructor.
+type UnsyncStack[E any] struct {
+	// elems is the underlying array.
+	elems []E
+}
+
+// Push implements CoreStack.
+func (us *UnsyncStack[E]) Push(e E) error {
+	if us == nil {
+		return common.ErrNilReceiver
+	}
+
+	us.elems = append(us.elems, e)
+
+	return nil
+}
+
+// Pop implements CoreStack.
+func (us *UnsyncStack[E]) Pop() (E, error) {
+	if us == nil {
+		return *new(E), common.ErrNilReceiver
+	}
+
+	if len(us.elems) == 0 {
+		return *new(E), ErrEmptyStack
+	}
+
+	e := us.elems[len(us.elems)-1]
+
+	us.elems[len(us.elems)-1] = *new(E)
+	us.elems = us.elems[:len(us.elems)-1]
+
+	return e, nil
+}
+
+// Peek returns the element on top of the stack without removing it.
+//
+// Returns:
+//   - E: The element on top of the stack.
+//   - error: An error if the stack is nil or empty.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - ErrEmptyStack: If the stack is empty.
+func (us *UnsyncStack[E]) Peek() (E, error) {
+	if us == nil {
+		return *new(E), common.ErrNilReceiver
+	} else if len(us.elems) == 0 {
+		return *new(E), ErrEmptyStack
+	}
+
+	return us.elems[len(us.elems)-1], nil
+}
+
+// IsEmpty implements CoreStack.
+func (us *UnsyncStack[E]) IsEmpty() bool {
+	ok := us == nil || len(us.elems) == 0
+	return ok
+}
+
+// Slice implements Collection.
+func (us *UnsyncStack[E]) Slice() []E {
+	if us == nil || len(us.elems) == 0 {
+		return nil
+	}
+
+	slice := make([]E, len(us.elems))
+	copy(slice, us.elems)
+
+	internal.Reverse(slice)
+
+	return slice
+}
+
+// Reset implements Collection.
+func (us *UnsyncStack[E]) Reset() error {
+	if us == nil {
+		return common.ErrNilReceiver
+	}
+
+	if len(us.elems) == 0 {
+		return nil
+	}
+
+	clear(us.elems)
+	us.elems = nil
+
+	return nil
+}
+
+// PushMany pushes all elements in the slice onto the stack in the order they are given in the slice.
+//
+// Parameters:
+//   - elems: The elements to push onto the stack.
+//
+// Returns:
+//   - error: An error if the elements could not be pushed onto the stack.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the stack is nil.
+func (us *UnsyncStack[E]) PushMany(elems []E) error {
+	if us == nil {
+		return common.ErrNilReceiver
+	}
+
+	for i := len(elems) - 1; i >= 0; i-- {
+		us.elems = append(us.elems, elems[i])
+	}
+
+	return nil
+}
+
+// dropBottom removes the n bottom-most elements of the stack.
+//
+// Parameters:
+//   - n: The number of elements to remove. Assumed to be at most the size of
+//     the stack.
+//
+// Returns:
+//   - error: An error if the receiver is nil.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+func (us *UnsyncStack[E]) dropBottom(n int) error {
+	if us == nil {
+		return common.ErrNilReceiver
+	}
+
+	size := copy(us.elems, us.elems[n:])
+
+	clear(us.elems[size:])
+	us.elems = us.elems[:size]
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package tree
+
+const (
+	// DefaultArenaBlockSize is the number of nodes per block of an Arena whose
+	// block size is not positive.
+	DefaultArenaBlockSize int = 1024
+)
+
+// Arena allocates nodes from contiguous blocks instead of one by one, which
+// reduces the number of allocations when building large trees. The nodes are
+// regular GenericNode values and can be used with every function of the
+// package.
+//
+// The nodes of an arena are all freed at once by Reset or Free; they must not
+// be used afterwards. An Arena is not safe for concurrent use.
+type Arena[T comparable, D any] struct {
+	// blocks are the blocks of nodes. Blocks are never reallocated so that the
+	// pointers to their nodes stay valid.
+	blocks [][]GenericNode[T, D]
+
+	// current is the index of the block nodes are allocated from.
+	current int
+
+	// used is the number of nodes allocated from the current block.
+	used int
+
+	// block_size is the number of nodes per block.
+	block_size int
+}
+
+// NewArena creates a new, empty arena.
+//
+// Parameters:
+//   - block_size: The number of nodes per block. If zero or negative,
+//     DefaultArenaBlockSize is used.
+//
+// Returns:
+//   - *Arena[T, D]: The new arena. Never returns nil.
+func NewArena[T comparable, D any](block_size int) *Arena[T, D] {
+	if block_size <= 0 {
+		block_size = DefaultArenaBlockSize
+	}
+
+	a := &Arena[T, D]{
+		block_size: block_size,
+	}
+
+	return a
+}
+
+// New allocates a new node with the specified type and data from the arena.
+//
+// Parameters:
+//   - type_: The type of the node.
+//   - data: The data associated with the node.
+//
+// Returns:
+//   - *GenericNode[T, D]: The new node. Nil if the receiver is nil.
+func (a *Arena[T, D]) New(type_ T, data D) *GenericNode[T, D] {
+	if a == nil {
+		return nil
+	}
+
+	if a.block_size <= 0 {
+		a.block_size = DefaultArenaBlockSize
+	}
+
+	if len(a.blocks) == 0 {
+		a.blocks = append(a.blocks, make([]GenericNode[T, D], a.block_size))
+	} else if a.used == len(a.blocks[a.current]) {
+		a.current++
+		a.used = 0
+
+		if a.current == len(a.blocks) {
+			a.blocks = append(a.blocks, make([]GenericNode[T, D], a.block_size))
+		}
+	}
+
+	n := &a.blocks[a.current][a.used]
+	a.used++
+
+	n.Type = type_
+	n.Data = data
+
+	return n
+}
+
+// Len returns the number of nodes allocated from the arena since its creation
+// or its last Reset or Free.
+//
+// Returns:
+//   - int: The number of allocated nodes.
+func (a *Arena[T, D]) Len() int {
+	if a == nil || len(a.blocks) == 0 {
+		return 0
+	}
+
+	n := a.current*a.block_size + a.used
+	return n
+}
+
+// Reset frees all the nodes of the arena at once but keeps its blocks so that
+// they are reused by the next allocations. The nodes are cleared so that the
+// data they referenced can be garbage collected.
+func (a *Arena[T, D]) Reset() {
+	if a == nil || len(a.blocks) == 0 {
+		return
+	}
+
+	for i := 0; i < a.current; i++ {
+		clear(a.blocks[i])
+	}
+
+	clear(a.blocks[a.current][:a.used])
+
+	a.current = 0
+	a.used = 0
+}
+
+// Free frees all the nodes of the arena at once and releases its blocks.
+func (a *Arena[T, D]) Free() {
+	if a == nil {
+		return
+	}
+
+	clear(a.blocks)
+
+	a.blocks = nil
+	a.current = 0
+	a.used = 0
+}
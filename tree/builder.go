@@ -0,0 +1,242 @@
+package tree
+
+import (
+	"errors"
+
+	common "github.com/PlayerR9/mygo-data/common"
+	"github.com/PlayerR9/mygo-data/stack"
+)
+
+// Builder builds a tree bottom-up the way a shift-reduce parser does: nodes
+// are shifted onto a stack and reductions replace the nodes on top of the
+// stack with a new parent. A reduction that fails leaves the stack as it was;
+// if the stack cannot be restored, the builder is broken and every later
+// operation fails with ErrBrokenBuilder.
+type Builder struct {
+	// stack holds the roots of the subtrees built so far. Its popped elements
+	// are those of the reduction in progress.
+	stack *stack.RefusableStack[*BaseNode]
+
+	// broken is true once a failed operation could not restore the stack.
+	broken bool
+}
+
+// NewBuilder creates a new builder on top of an empty stack.ArrayStack.
+//
+// Returns:
+//   - *Builder: The new builder. Never returns nil.
+func NewBuilder() *Builder {
+	s, _ := stack.RefusableOf[*BaseNode](new(stack.ArrayStack[*BaseNode])) // The stack is not nil.
+
+	b := &Builder{
+		stack: s,
+	}
+
+	return b
+}
+
+// BuilderOf creates a new builder on top of the given stack. The nodes already
+// on the stack are the initial subtrees.
+//
+// Parameters:
+//   - s: The stack to build on.
+//
+// Returns:
+//   - *Builder: The new builder. Nil if an error occurred.
+//   - error: An error if the stack is nil.
+//
+// Errors:
+//   - common.ErrBadParam: If the stack is nil.
+func BuilderOf(s stack.Stack[*BaseNode]) (*Builder, error) {
+	if s == nil {
+		err := common.NewErrNilParam("s")
+		return nil, err
+	}
+
+	rs, err := stack.RefusableOf(s)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Builder{
+		stack: rs,
+	}
+
+	return b, nil
+}
+
+// Nodes returns the roots of the subtrees built so far.
+//
+// Returns:
+//   - []*BaseNode: The nodes on the stack, from the top to the bottom. Nil if
+//     the stack is empty.
+func (b *Builder) Nodes() []*BaseNode {
+	if b == nil {
+		return nil
+	}
+
+	nodes := b.stack.Slice()
+	return nodes
+}
+
+// Shift pushes the node onto the stack.
+//
+// Parameters:
+//   - node: The node to push. It must be the root of its own tree.
+//
+// Returns:
+//   - error: An error if the node could not be pushed.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If the node is nil or has a parent.
+//   - ErrBrokenBuilder: If the builder is broken.
+//   - any other error: Returned by the underlying stack.
+func (b *Builder) Shift(node *BaseNode) error {
+	if b == nil {
+		return common.ErrNilReceiver
+	} else if b.broken {
+		return ErrBrokenBuilder
+	} else if node == nil {
+		err := common.NewErrNilParam("node")
+		return err
+	} else if node.Parent != nil {
+		err := common.NewErrBadParam("node", "must not have a parent")
+		return err
+	}
+
+	err := b.stack.Push(node)
+	return err
+}
+
+// refuse restores the popped nodes onto the stack after a failed operation.
+// If they cannot be restored, the popped nodes are dropped so that no later
+// operation commits them, and the builder is broken.
+//
+// Parameters:
+//   - err: The error of the failed operation.
+//
+// Returns:
+//   - error: The error, joined with the error of the stack if the nodes could
+//     not be restored.
+func (b *Builder) refuse(err error) error {
+	refuse_err := b.stack.Refuse()
+	if refuse_err != nil {
+		_ = b.stack.Accept()
+		b.broken = true
+
+		err = errors.Join(err, refuse_err)
+	}
+
+	return err
+}
+
+// Reduce pops the n nodes on top of the stack and pushes a new node that has
+// them as children, in the order they were shifted. The span of the new node
+// is the union of the spans of its children. If the reduction fails, the
+// stack and the popped nodes are restored; if they cannot be, the builder is
+// broken.
+//
+// Parameters:
+//   - type_: The type of the new node.
+//   - data: The data of the new node.
+//   - n: The number of nodes to pop. If zero, the new node is a leaf.
+//
+// Returns:
+//   - *BaseNode: The new node. Nil if an error occurred.
+//   - error: An error if the reduction failed.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If n is negative or greater than the number of nodes
+//     on the stack.
+//   - *ErrInvariant: If the debug mode is enabled and a modified tree is
+//     inconsistent. See SetDebug.
+//   - ErrBrokenBuilder: If the builder is broken.
+//   - any other error: Returned by the underlying stack. If the popped nodes
+//     could not be restored, it is joined with a *stack.ErrAborted.
+func (b *Builder) Reduce(type_, data string, n int) (*BaseNode, error) {
+	if b == nil {
+		return nil, common.ErrNilReceiver
+	} else if b.broken {
+		return nil, ErrBrokenBuilder
+	} else if n < 0 {
+		err := common.NewErrBadParam("n", "must not be negative")
+		return nil, err
+	}
+
+	children := make([]*BaseNode, n)
+
+	for i := n - 1; i >= 0; i-- {
+		top, err := b.stack.Pop()
+		if err == stack.ErrEmptyStack {
+			err := common.NewErrBadParam("n", "must not be greater than the number of nodes on the stack")
+			err = b.refuse(err)
+
+			return nil, err
+		} else if err != nil {
+			err = b.refuse(err)
+
+			return nil, err
+		}
+
+		children[i] = top
+	}
+
+	parent := NewBaseNode(type_, data)
+
+	err := AppendChildren(parent, children)
+	if err == nil {
+		unionChildSpans(parent)
+
+		err = b.stack.Push(parent)
+	}
+
+	if err != nil {
+		_, _ = parent.RemoveChildren()
+		err = b.refuse(err)
+
+		return nil, err
+	}
+
+	_ = b.stack.Accept()
+
+	return parent, nil
+}
+
+// Build pops the root of the built tree, which must be the only node left on
+// the stack. If an error occurs, the stack is restored; if it cannot be, the
+// builder is broken.
+//
+// Returns:
+//   - *BaseNode: The root of the built tree. Nil if an error occurred.
+//   - error: An error if the stack does not hold exactly one node.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - stack.ErrEmptyStack: If the stack is empty.
+//   - ErrUnreducedStack: If more than one node is on the stack. If the root
+//     could not be pushed back, it is joined with a *stack.ErrAborted.
+//   - ErrBrokenBuilder: If the builder is broken.
+//   - any other error: Returned by the underlying stack.
+func (b *Builder) Build() (*BaseNode, error) {
+	if b == nil {
+		return nil, common.ErrNilReceiver
+	} else if b.broken {
+		return nil, ErrBrokenBuilder
+	}
+
+	root, err := b.stack.Pop()
+	if err != nil {
+		return nil, err
+	}
+
+	if !b.stack.IsEmpty() {
+		err := b.refuse(ErrUnreducedStack)
+		return nil, err
+	}
+
+	_ = b.stack.Accept()
+
+	return root, nil
+}
@@ -0,0 +1,99 @@
+package tree
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/PlayerR9/mygo-data/stack"
+)
+
+// errPushFailed is the error returned by a failingStack.
+var errPushFailed = errors.New("push failed")
+
+// failingStack is a stack whose Push fails once it has succeeded a given
+// number of times.
+type failingStack struct {
+	stack.ArrayStack[*BaseNode]
+
+	// pushes is the number of pushes left before Push fails.
+	pushes int
+}
+
+// Push implements stack.CoreStack.
+func (s *failingStack) Push(e *BaseNode) error {
+	if s.pushes == 0 {
+		return errPushFailed
+	}
+
+	s.pushes--
+
+	err := s.ArrayStack.Push(e)
+	return err
+}
+
+// TestBuilder checks that shifts and reductions build the expected tree.
+func TestBuilder(t *testing.T) {
+	b := NewBuilder()
+
+	_ = b.Shift(NewBaseNode("Num", "1"))
+	_ = b.Shift(NewBaseNode("Num", "2"))
+
+	_, err := b.Reduce("Add", "+", 3)
+	if err == nil {
+		t.Fatal("Reduce() popped more nodes than the stack holds")
+	} else if len(b.Nodes()) != 2 {
+		t.Fatalf("a failed Reduce() left %d nodes, want 2", len(b.Nodes()))
+	}
+
+	_, err = b.Reduce("Add", "+", 2)
+	if err != nil {
+		t.Fatalf("Reduce() = %v", err)
+	}
+
+	root, err := b.Build()
+	if err != nil {
+		t.Fatalf("Build() = %v", err)
+	}
+
+	if got, want := sexprOf(t, root), `(Add "+" (Num "1") (Num "2"))`; got != want {
+		t.Fatalf("Build() = %s, want %s", got, want)
+	}
+}
+
+// TestBuilderBroken checks that a reduction whose nodes cannot be restored
+// breaks the builder instead of leaving them pending for a later reduction.
+func TestBuilderBroken(t *testing.T) {
+	fs := &failingStack{pushes: 3}
+
+	b, _ := BuilderOf(fs)
+
+	for _, data := range []string{"1", "2", "3"} {
+		_ = b.Shift(NewBaseNode("Num", data))
+	}
+
+	// Pushing the parent fails, and so does restoring its children.
+	_, err := b.Reduce("Add", "+", 2)
+
+	var aborted *stack.ErrAborted
+
+	if !errors.Is(err, errPushFailed) || !errors.As(err, &aborted) {
+		t.Fatalf("Reduce() = %v, want the push error joined with an *stack.ErrAborted", err)
+	}
+
+	fs.pushes = 10
+
+	_, err = b.Reduce("Leaf", "", 0)
+	if err != ErrBrokenBuilder {
+		t.Fatalf("Reduce() on a broken builder = %v, want %v", err, ErrBrokenBuilder)
+	}
+
+	err = b.Shift(NewBaseNode("Num", "4"))
+	if err != ErrBrokenBuilder {
+		t.Fatalf("Shift() on a broken builder = %v, want %v", err, ErrBrokenBuilder)
+	}
+
+	_, err = b.Build()
+	if err != ErrBrokenBuilder {
+		t.Fatalf("Build() on a broken builder = %v, want %v", err, ErrBrokenBuilder)
+	}
+}
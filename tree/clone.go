@@ -0,0 +1,236 @@
+package tree
+
+import (
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+)
+
+// Clone returns a deep copy of the tree rooted at root. The copy is fully
+// linked and independent from the original: its root has no parent nor
+// siblings. The data is copied by assignment and the spans are copied. The
+// copy is not recursive.
+//
+// Parameters:
+//   - root: The root of the tree to copy.
+//
+// Returns:
+//   - *GenericNode[T, D]: The root of the copy. Nil if the root is nil.
+func Clone[T comparable, D any](root *GenericNode[T, D]) *GenericNode[T, D] {
+	if root == nil {
+		return nil
+	}
+
+	type frame struct {
+		// node is the node to copy.
+		node *GenericNode[T, D]
+
+		// parent is the copy of the node's parent.
+		parent *GenericNode[T, D]
+	}
+
+	copy_root := NewGenericNode(root.Type, root.Data)
+	copy_root.Span = copySpan(root.Span)
+
+	var stack []frame
+
+	for c := root.LastChild; c != nil; c = c.PrevSibling {
+		stack = append(stack, frame{node: c, parent: copy_root})
+	}
+
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		c := NewGenericNode(top.node.Type, top.node.Data)
+		c.Span = copySpan(top.node.Span)
+
+		_ = top.parent.AppendChild(c)
+
+		for child := top.node.LastChild; child != nil; child = child.PrevSibling {
+			stack = append(stack, frame{node: child, parent: c})
+		}
+	}
+
+	return copy_root
+}
+
+// copySpan returns a copy of the span.
+//
+// Parameters:
+//   - span: The span to copy.
+//
+// Returns:
+//   - *Span: The copy. Nil if the span is nil.
+func copySpan(span *Span) *Span {
+	if span == nil {
+		return nil
+	}
+
+	s := *span
+	return &s
+}
+
+// Equal checks whether two trees are structurally equal: same types, same data
+// and same children in the same order. Spans are ignored. The comparison is
+// not recursive.
+//
+// Parameters:
+//   - a: The root of the first tree.
+//   - b: The root of the second tree.
+//
+// Returns:
+//   - bool: True if the trees are equal, false otherwise. Two nil trees are
+//     equal.
+func Equal[T comparable, D comparable](a, b *GenericNode[T, D]) bool {
+	ok := EqualFunc(a, b, func(x, y D) bool { return x == y })
+	return ok
+}
+
+// EqualFunc is like Equal but compares the data with the given function.
+//
+// Parameters:
+//   - a: The root of the first tree.
+//   - b: The root of the second tree.
+//   - eq: The function that compares the data of two nodes. If nil, the data is
+//     ignored and only the types and the shape are compared.
+//
+// Returns:
+//   - bool: True if the trees are equal, false otherwise. Two nil trees are
+//     equal.
+func EqualFunc[T comparable, D any](a, b *GenericNode[T, D], eq func(x, y D) bool) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	stack := [][2]*GenericNode[T, D]{{a, b}}
+
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		x, y := top[0], top[1]
+
+		if x.Type != y.Type {
+			return false
+		}
+
+		if eq != nil && !eq(x.Data, y.Data) {
+			return false
+		}
+
+		cx, cy := x.FirstChild, y.FirstChild
+
+		for cx != nil && cy != nil {
+			stack = append(stack, [2]*GenericNode[T, D]{cx, cy})
+
+			cx, cy = cx.NextSibling, cy.NextSibling
+		}
+
+		if cx != nil || cy != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// writeNodeHash writes the labels of the node and the hashes of its children
+// to the hash.
+//
+// Parameters:
+//   - h: The hash to write to.
+//   - node: The node to hash.
+//   - child_hashes: The hashes of the node's children, in order.
+func writeNodeHash(h hash.Hash64, node *BaseNode, child_hashes []uint64) {
+	var buf [8]byte
+
+	binary.LittleEndian.PutUint64(buf[:], uint64(len(node.Type)))
+	_, _ = h.Write(buf[:])
+	_, _ = h.Write([]byte(node.Type))
+
+	binary.LittleEndian.PutUint64(buf[:], uint64(len(node.Data)))
+	_, _ = h.Write(buf[:])
+	_, _ = h.Write([]byte(node.Data))
+
+	for _, ch := range child_hashes {
+		binary.LittleEndian.PutUint64(buf[:], ch)
+		_, _ = h.Write(buf[:])
+	}
+}
+
+// Hash returns a structural hash of the tree rooted at root. Equal trees have
+// the same hash. The hash is a 64-bit FNV-1a over the types, the data and the
+// shape, so it is stable across runs and processes and can be persisted. The
+// computation is not recursive.
+//
+// Parameters:
+//   - root: The root of the tree to hash.
+//
+// Returns:
+//   - uint64: The hash of the tree. Zero if the root is nil.
+func Hash(root *BaseNode) uint64 {
+	if root == nil {
+		return 0
+	}
+
+	h := fnv.New64a()
+
+	// PostOrder yields the children right before their parent, so their
+	// hashes are on top of the stack.
+	var stack []uint64
+
+	for n := range PostOrder(root) {
+		var count int
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			count++
+		}
+
+		h.Reset()
+		writeNodeHash(h, n, stack[len(stack)-count:])
+
+		stack = stack[:len(stack)-count]
+		stack = append(stack, h.Sum64())
+	}
+
+	return stack[0]
+}
+
+// SubtreeHashes returns the structural hash of every subtree of the tree
+// rooted at root, as computed by Hash. Identical subtrees have the same hash,
+// which is useful to memoize or deduplicate them. The computation is not
+// recursive.
+//
+// Parameters:
+//   - root: The root of the tree to hash.
+//
+// Returns:
+//   - map[*BaseNode]uint64: The hash of the subtree rooted at each node. Nil if
+//     the root is nil.
+func SubtreeHashes(root *BaseNode) map[*BaseNode]uint64 {
+	if root == nil {
+		return nil
+	}
+
+	hashes := make(map[*BaseNode]uint64)
+
+	h := fnv.New64a()
+
+	var child_hashes []uint64
+
+	for n := range PostOrder(root) {
+		child_hashes = child_hashes[:0]
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			child_hashes = append(child_hashes, hashes[c])
+		}
+
+		h.Reset()
+		writeNodeHash(h, n, child_hashes)
+
+		hashes[n] = h.Sum64()
+	}
+
+	return hashes
+}
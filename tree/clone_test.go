@@ -0,0 +1,200 @@
+package tree
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+// TestClone checks that Clone returns a deep copy that is equal to the
+// original, is a valid tree and shares no node nor span with it.
+func TestClone(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 4))
+
+	trees := []*BaseNode{spannedTree(), spannedTree().FirstChild}
+
+	for range 100 {
+		trees = append(trees, randomTree(rng, 1+rng.IntN(30)))
+	}
+
+	for _, root := range trees {
+		clone := Clone(root)
+
+		if !Equal(root, clone) {
+			t.Fatalf("Clone(%s) = %s", sexprOf(t, root), sexprOf(t, clone))
+		}
+
+		if err := Validate(clone); err != nil {
+			t.Fatalf("Clone(%s) is an invalid tree: %v", sexprOf(t, root), err)
+		}
+
+		if clone.Parent != nil || clone.PrevSibling != nil || clone.NextSibling != nil {
+			t.Fatalf("the clone of %v has a parent or siblings", root)
+		}
+
+		originals := make(map[*BaseNode]bool)
+
+		for n := range PreOrder(root) {
+			originals[n] = true
+		}
+
+		for a, b := range zipPreOrder(root, clone) {
+			if originals[b] {
+				t.Fatalf("the clone of %s shares the node %v", sexprOf(t, root), b)
+			}
+
+			if (a.Span == nil) != (b.Span == nil) || a.Span != nil && (a.Span == b.Span || *a.Span != *b.Span) {
+				t.Fatalf("the span of %v cloned as %v", a, b.Span)
+			}
+		}
+
+		// Changing the clone must not change the original.
+		want := sexprOf(t, root)
+
+		clone.Data += "'"
+		_ = clone.AppendChild(NewBaseNode("New", ""))
+
+		if got := sexprOf(t, root); got != want {
+			t.Fatalf("changing the clone changed the original to %s, want %s", got, want)
+		}
+	}
+
+	if Clone[string, string](nil) != nil {
+		t.Fatal("Clone(nil) != nil")
+	}
+}
+
+// TestEqual checks that Equal detects differences of type, data and shape,
+// and that it ignores spans.
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{`(A "x" (B) (C (D)))`, `(A "x" (B) (C (D)))`, true},
+		{`(A "x" (B) (C (D)))`, `(A "x" (B) (E (D)))`, false},
+		{`(A "x" (B) (C (D)))`, `(A "y" (B) (C (D)))`, false},
+		{`(A "x" (B) (C (D)))`, `(A "x" (B) (C (D "z")))`, false},
+		{`(A "x" (B) (C (D)))`, `(A "x" (B) (C))`, false},
+		{`(A "x" (B) (C (D)))`, `(A "x" (B) (C (D) (D)))`, false},
+		{`(A "x" (B) (C (D)))`, `(A "x" (C (D)) (B))`, false},
+		{`(A (B (C)))`, `(A (B) (C))`, false},
+		{`(A "bc")`, `(Ab "c")`, false},
+		{`(A @0-1)`, `(A @5-9)`, true},
+	}
+
+	for _, tt := range tests {
+		a, b := mustSExpr(t, tt.a), mustSExpr(t, tt.b)
+
+		if got := Equal(a, b); got != tt.want {
+			t.Errorf("Equal(%s, %s) = %t, want %t", tt.a, tt.b, got, tt.want)
+		}
+
+		if got := Equal(b, a); got != tt.want {
+			t.Errorf("Equal(%s, %s) = %t, want %t", tt.b, tt.a, got, tt.want)
+		}
+	}
+
+	a := mustSExpr(t, `(A "x")`)
+
+	if Equal(a, nil) || Equal(nil, a) || !Equal[string, string](nil, nil) {
+		t.Error("Equal() does not handle nil trees")
+	}
+}
+
+// TestEqualFunc checks that EqualFunc compares the data with the given
+// function and ignores it when the function is nil.
+func TestEqualFunc(t *testing.T) {
+	a := NewGenericNode("A", []int{1, 2})
+	_ = a.AppendChild(NewGenericNode("B", []int{3}))
+
+	b := NewGenericNode("A", []int{1, 2})
+	_ = b.AppendChild(NewGenericNode("B", []int{4}))
+
+	sameLen := func(x, y []int) bool { return len(x) == len(y) }
+	sameFirst := func(x, y []int) bool { return x[0] == y[0] }
+
+	if !EqualFunc(a, b, sameLen) {
+		t.Error("EqualFunc() with equal lengths = false, want true")
+	}
+
+	if EqualFunc(a, b, sameFirst) {
+		t.Error("EqualFunc() with different first elements = true, want false")
+	}
+
+	if !EqualFunc(a, b, nil) {
+		t.Error("EqualFunc() with a nil function = false, want true")
+	}
+
+	_ = b.FirstChild.AppendChild(NewGenericNode("C", []int{5}))
+
+	if EqualFunc(a, b, nil) {
+		t.Error("EqualFunc() with a nil function ignored the shape")
+	}
+}
+
+// TestHash checks that equal trees have the same hash, that the trees of
+// TestEqual that differ have different hashes and that SubtreeHashes agrees
+// with Hash on every subtree.
+func TestHash(t *testing.T) {
+	rng := rand.New(rand.NewPCG(5, 6))
+
+	for range 100 {
+		root := randomTree(rng, 1+rng.IntN(30))
+
+		if Hash(root) != Hash(Clone(root)) {
+			t.Fatalf("Hash() differs between %s and its clone", sexprOf(t, root))
+		}
+
+		hashes := SubtreeHashes(root)
+
+		for n := range PreOrder(root) {
+			if hashes[n] != Hash(n) {
+				t.Fatalf("SubtreeHashes()[%v] = %d, want %d", n, hashes[n], Hash(n))
+			}
+		}
+	}
+
+	distinct := []string{
+		`(A "x" (B) (C (D)))`,
+		`(A "x" (B) (E (D)))`,
+		`(A "y" (B) (C (D)))`,
+		`(A "x" (B) (C (D "z")))`,
+		`(A "x" (B) (C))`,
+		`(A "x" (C (D)) (B))`,
+		`(A (B (C)))`,
+		`(A (B) (C))`,
+		`(A "bc")`,
+		`(Ab "c")`,
+		`(A)`,
+	}
+
+	seen := make(map[uint64]string)
+
+	for _, str := range distinct {
+		h := Hash(mustSExpr(t, str))
+
+		if other, ok := seen[h]; ok {
+			t.Errorf("Hash(%s) = Hash(%s)", str, other)
+		}
+
+		seen[h] = str
+	}
+
+	if Hash(mustSExpr(t, `(A @0-1)`)) != Hash(mustSExpr(t, `(A @5-9)`)) {
+		t.Error("Hash() depends on the spans")
+	}
+
+	// The two (B (C)) subtrees are identical.
+	root := mustSExpr(t, `(A (B (C)) (B (C)) (B))`)
+	hashes := SubtreeHashes(root)
+
+	first, second, third := root.FirstChild, root.FirstChild.NextSibling, root.LastChild
+
+	if hashes[first] != hashes[second] || hashes[first] == hashes[third] {
+		t.Errorf("SubtreeHashes() = %d, %d, %d for (B (C)), (B (C)), (B)", hashes[first], hashes[second], hashes[third])
+	}
+
+	if Hash(nil) != 0 || SubtreeHashes(nil) != nil {
+		t.Error("Hash() or SubtreeHashes() does not handle a nil tree")
+	}
+}
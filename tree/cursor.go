@@ -0,0 +1,358 @@
+package tree
+
+import (
+	common "github.com/PlayerR9/mygo-data/common"
+)
+
+// Cursor is a position in a tree that can be moved around and used to edit
+// the tree at that position. The cursor never leaves the tree it was created
+// on: moving up from its root or sideways from its root fails.
+//
+// The tree should only be modified through the cursor while it is in use.
+// Otherwise, the cursor may end up on a node that is no longer part of the
+// tree.
+type Cursor[T comparable, D any] struct {
+	// root is the root of the tree.
+	root *GenericNode[T, D]
+
+	// node is the current node.
+	node *GenericNode[T, D]
+
+	// depth is the depth of the current node.
+	depth int
+}
+
+// NewCursor returns a cursor positioned on the root of the tree.
+//
+// Parameters:
+//   - root: The root of the tree.
+//
+// Returns:
+//   - *Cursor[T, D]: The new cursor. Nil if the root is nil.
+func NewCursor[T comparable, D any](root *GenericNode[T, D]) *Cursor[T, D] {
+	if root == nil {
+		return nil
+	}
+
+	c := &Cursor[T, D]{
+		root: root,
+		node: root,
+	}
+
+	return c
+}
+
+// Node returns the current node.
+//
+// Returns:
+//   - *GenericNode[T, D]: The current node. Nil if the receiver is nil.
+func (c *Cursor[T, D]) Node() *GenericNode[T, D] {
+	if c == nil {
+		return nil
+	}
+
+	return c.node
+}
+
+// Depth returns the depth of the current node, where the root is at depth 0.
+//
+// Returns:
+//   - int: The depth of the current node.
+func (c *Cursor[T, D]) Depth() int {
+	if c == nil {
+		return 0
+	}
+
+	return c.depth
+}
+
+// Path returns the path of child indexes from the root to the current node.
+//
+// Returns:
+//   - []int: The path of child indexes. Nil if the cursor is on the root.
+func (c *Cursor[T, D]) Path() []int {
+	if c == nil {
+		return nil
+	}
+
+	path := pathOf(c.root, c.node)
+	return path
+}
+
+// Root moves the cursor to the root of the tree.
+//
+// Returns:
+//   - *GenericNode[T, D]: The root of the tree, taking into account the
+//     replacements of the root. Nil if the receiver is nil.
+func (c *Cursor[T, D]) Root() *GenericNode[T, D] {
+	if c == nil {
+		return nil
+	}
+
+	c.node = c.root
+	c.depth = 0
+
+	return c.root
+}
+
+// Up moves the cursor to the parent of the current node. On error, the
+// cursor does not move.
+//
+// Returns:
+//   - error: An error if the cursor could not be moved.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - ErrNoParent: If the cursor is on the root.
+func (c *Cursor[T, D]) Up() error {
+	if c == nil {
+		return common.ErrNilReceiver
+	}
+
+	if c.node == c.root || c.node.Parent == nil {
+		return ErrNoParent
+	}
+
+	c.node = c.node.Parent
+	c.depth--
+
+	return nil
+}
+
+// Down moves the cursor to the n-th child of the current node. On error, the
+// cursor does not move.
+//
+// Parameters:
+//   - n: The index of the child, starting from 0.
+//
+// Returns:
+//   - error: An error if the cursor could not be moved.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If n is negative.
+//   - ErrNoChild: If the current node has no n-th child.
+func (c *Cursor[T, D]) Down(n int) error {
+	if c == nil {
+		return common.ErrNilReceiver
+	} else if n < 0 {
+		err := common.NewErrBadParam("n", "must not be negative")
+		return err
+	}
+
+	child := c.node.FirstChild
+
+	for i := 0; i < n && child != nil; i++ {
+		child = child.NextSibling
+	}
+
+	if child == nil {
+		return ErrNoChild
+	}
+
+	c.node = child
+	c.depth++
+
+	return nil
+}
+
+// Next moves the cursor to the next sibling of the current node. On error,
+// the cursor does not move.
+//
+// Returns:
+//   - error: An error if the cursor could not be moved.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - ErrNoSibling: If the current node is the last child or the root.
+func (c *Cursor[T, D]) Next() error {
+	if c == nil {
+		return common.ErrNilReceiver
+	}
+
+	if c.node == c.root || c.node.NextSibling == nil {
+		return ErrNoSibling
+	}
+
+	c.node = c.node.NextSibling
+
+	return nil
+}
+
+// Prev moves the cursor to the previous sibling of the current node. On
+// error, the cursor does not move.
+//
+// Returns:
+//   - error: An error if the cursor could not be moved.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - ErrNoSibling: If the current node is the first child or the root.
+func (c *Cursor[T, D]) Prev() error {
+	if c == nil {
+		return common.ErrNilReceiver
+	}
+
+	if c.node == c.root || c.node.PrevSibling == nil {
+		return ErrNoSibling
+	}
+
+	c.node = c.node.PrevSibling
+
+	return nil
+}
+
+// Replace replaces the current node with the given node, which becomes the
+// current node. The old node is detached and keeps its children, so the new
+// node may be one of them. If the current node is the root of the cursor, the
+// new node becomes its root; if the cursor was created on a subtree, the new
+// node also takes the place of the old one in its parent.
+//
+// Parameters:
+//   - node: The node that replaces the current node. If it already has a
+//     parent, it is detached first.
+//
+// Returns:
+//   - error: An error if the node could not be replaced.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If the node is nil or an ancestor of the current
+//     node.
+//   - *ErrInvariant: If the debug mode is enabled and a modified tree is
+//     inconsistent. See SetDebug.
+func (c *Cursor[T, D]) Replace(node *GenericNode[T, D]) error {
+	if c == nil {
+		return common.ErrNilReceiver
+	} else if node == nil {
+		err := common.NewErrNilParam("node")
+		return err
+	}
+
+	if node == c.node {
+		return nil
+	}
+
+	if c.node.Parent != nil {
+		err := c.node.ReplaceWith(node)
+		if err != nil {
+			return err
+		}
+	} else {
+		if isAncestorOf(node, c.node) {
+			err := common.NewErrBadParam("node", "must not be an ancestor of the current node")
+			return err
+		}
+
+		err := node.Detach()
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.node == c.root {
+		c.root = node
+	}
+
+	c.node = node
+
+	return nil
+}
+
+// InsertLeft inserts the given node as the previous sibling of the current
+// node. The cursor does not move.
+//
+// Parameters:
+//   - node: The node to insert. If it already has a parent, it is detached
+//     first.
+//
+// Returns:
+//   - error: An error if the node could not be inserted.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - ErrNoParent: If the cursor is on the root.
+//   - common.ErrBadParam: If the node is nil, the current node or one of its
+//     ancestors.
+//   - *ErrInvariant: If the debug mode is enabled and a modified tree is
+//     inconsistent. See SetDebug.
+func (c *Cursor[T, D]) InsertLeft(node *GenericNode[T, D]) error {
+	if c == nil {
+		return common.ErrNilReceiver
+	} else if node == nil {
+		err := common.NewErrNilParam("node")
+		return err
+	} else if c.node == c.root {
+		return ErrNoParent
+	}
+
+	err := c.node.InsertBefore(node)
+	return err
+}
+
+// InsertRight inserts the given node as the next sibling of the current node.
+// The cursor does not move.
+//
+// Parameters:
+//   - node: The node to insert. If it already has a parent, it is detached
+//     first.
+//
+// Returns:
+//   - error: An error if the node could not be inserted.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - ErrNoParent: If the cursor is on the root.
+//   - common.ErrBadParam: If the node is nil, the current node or one of its
+//     ancestors.
+//   - *ErrInvariant: If the debug mode is enabled and a modified tree is
+//     inconsistent. See SetDebug.
+func (c *Cursor[T, D]) InsertRight(node *GenericNode[T, D]) error {
+	if c == nil {
+		return common.ErrNilReceiver
+	} else if node == nil {
+		err := common.NewErrNilParam("node")
+		return err
+	} else if c.node == c.root {
+		return ErrNoParent
+	}
+
+	err := c.node.InsertAfter(node)
+	return err
+}
+
+// Delete removes the current node, with its subtree, from the tree. The
+// cursor moves to the next sibling if there is one, otherwise to the previous
+// sibling, otherwise to the parent.
+//
+// Returns:
+//   - *GenericNode[T, D]: The removed node. Nil if the cursor is on the root.
+//   - error: An error if the current node could not be removed.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - ErrNoParent: If the cursor is on the root.
+//   - *ErrInvariant: If the debug mode is enabled and a modified tree is
+//     inconsistent. See SetDebug.
+func (c *Cursor[T, D]) Delete() (*GenericNode[T, D], error) {
+	if c == nil {
+		return nil, common.ErrNilReceiver
+	} else if c.node == c.root {
+		return nil, ErrNoParent
+	}
+
+	removed := c.node
+
+	switch {
+	case removed.NextSibling != nil:
+		c.node = removed.NextSibling
+	case removed.PrevSibling != nil:
+		c.node = removed.PrevSibling
+	default:
+		c.node = removed.Parent
+		c.depth--
+	}
+
+	err := removed.Detach()
+	return removed, err
+}
@@ -0,0 +1,255 @@
+package tree
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	common "github.com/PlayerR9/mygo-data/common"
+)
+
+// TestCursorMoves checks the moves of a cursor and that it does not move when
+// a move fails at the edges of the tree.
+func TestCursorMoves(t *testing.T) {
+	root := mustSExpr(t, `(R (A (X) (Y)) (B))`)
+	c := NewCursor(root)
+
+	check := func(step string, err, want_err error, type_ string, path []int) {
+		t.Helper()
+
+		if err != want_err {
+			t.Fatalf("%s = %v, want %v", step, err, want_err)
+		}
+
+		if c.Node().Type != type_ || !slices.Equal(c.Path(), path) || c.Depth() != len(path) {
+			t.Fatalf("after %s the cursor is on %v at %v (depth %d), want %s at %v",
+				step, c.Node(), c.Path(), c.Depth(), type_, path)
+		}
+	}
+
+	check("Up() on the root", c.Up(), ErrNoParent, "R", nil)
+	check("Next() on the root", c.Next(), ErrNoSibling, "R", nil)
+	check("Prev() on the root", c.Prev(), ErrNoSibling, "R", nil)
+	check("Down(2)", c.Down(2), ErrNoChild, "R", nil)
+	check("Down(0)", c.Down(0), nil, "A", []int{0})
+	check("Prev() on the first child", c.Prev(), ErrNoSibling, "A", []int{0})
+	check("Down(1)", c.Down(1), nil, "Y", []int{0, 1})
+	check("Down(0) on a leaf", c.Down(0), ErrNoChild, "Y", []int{0, 1})
+	check("Next() on the last child", c.Next(), ErrNoSibling, "Y", []int{0, 1})
+	check("Prev()", c.Prev(), nil, "X", []int{0, 0})
+	check("Next()", c.Next(), nil, "Y", []int{0, 1})
+	check("Up()", c.Up(), nil, "A", []int{0})
+	check("Next()", c.Next(), nil, "B", []int{1})
+
+	var bad_param *common.ErrBadParam
+
+	if err := c.Down(-1); !errors.As(err, &bad_param) {
+		t.Fatalf("Down(-1) = %v, want a *common.ErrBadParam", err)
+	}
+
+	if got := c.Root(); got != root || c.Node() != root || c.Depth() != 0 {
+		t.Fatalf("Root() = %v, want %v", got, root)
+	}
+
+	// A cursor on a subtree never leaves it.
+	c = NewCursor(root.FirstChild)
+
+	check("Up() on a subtree root", c.Up(), ErrNoParent, "A", nil)
+	check("Next() on a subtree root", c.Next(), ErrNoSibling, "A", nil)
+	check("Down(1)", c.Down(1), nil, "Y", []int{1})
+}
+
+// TestCursorReplace checks that Replace puts the new node in place of the
+// current one, including on the root of the tree and of a subtree.
+func TestCursorReplace(t *testing.T) {
+	root := mustSExpr(t, `(R (A (X) (Y)) (B))`)
+	c := NewCursor(root)
+
+	_ = c.Down(0)
+	_ = c.Down(1)
+
+	err := c.Replace(NewBaseNode("Z", ""))
+	if err != nil {
+		t.Fatalf("Replace() = %v", err)
+	}
+
+	if got := sexprOf(t, root); got != `(R (A (X) (Z)) (B))` || c.Node().Type != "Z" {
+		t.Fatalf("Replace() produced %s with the cursor on %v", got, c.Node())
+	}
+
+	// The new node may be a child of the old one.
+	_ = c.Up()
+
+	err = c.Replace(c.Node().FirstChild)
+	if err != nil {
+		t.Fatalf("Replace() with a child = %v", err)
+	}
+
+	if got := sexprOf(t, root); got != `(R (X) (B))` || c.Node().Type != "X" {
+		t.Fatalf("Replace() with a child produced %s with the cursor on %v", got, c.Node())
+	}
+
+	// The root of the tree is replaced by the new node.
+	c.Root()
+
+	new_root := NewBaseNode("S", "")
+
+	err = c.Replace(new_root)
+	if err != nil {
+		t.Fatalf("Replace() on the root = %v", err)
+	}
+
+	if c.Root() != new_root || new_root.Parent != nil {
+		t.Fatalf("Replace() on the root did not make the new node the root")
+	}
+
+	// The root of a subtree is replaced in its parent.
+	root = mustSExpr(t, `(R (A (X) (Y)) (B))`)
+	c = NewCursor(root.FirstChild)
+
+	err = c.Replace(NewBaseNode("C", ""))
+	if err != nil {
+		t.Fatalf("Replace() on a subtree root = %v", err)
+	}
+
+	if got := sexprOf(t, root); got != `(R (C) (B))` || c.Root().Type != "C" {
+		t.Fatalf("Replace() on a subtree root produced %s with the root %v", got, c.Root())
+	}
+
+	if err := Validate(root); err != nil {
+		t.Fatalf("Replace() produced an invalid tree: %v", err)
+	}
+
+	// Nil nodes and ancestors are rejected without changes.
+	_ = c.Down(0)
+
+	var bad_param *common.ErrBadParam
+
+	if err := c.Replace(nil); !errors.As(err, &bad_param) {
+		t.Fatalf("Replace(nil) = %v, want a *common.ErrBadParam", err)
+	}
+
+	c = NewCursor(root.FirstChild)
+
+	if err := c.Replace(root); !errors.As(err, &bad_param) {
+		t.Fatalf("Replace() with an ancestor = %v, want a *common.ErrBadParam", err)
+	}
+
+	if got := sexprOf(t, root); got != `(R (C) (B))` {
+		t.Fatalf("failed replacements changed the tree to %s", got)
+	}
+}
+
+// TestCursorInsert checks that InsertLeft and InsertRight insert siblings
+// without moving the cursor and that they fail on the root.
+func TestCursorInsert(t *testing.T) {
+	root := mustSExpr(t, `(R (A) (B))`)
+	c := NewCursor(root)
+
+	if err := c.InsertLeft(NewBaseNode("L", "")); err != ErrNoParent {
+		t.Fatalf("InsertLeft() on the root = %v, want %v", err, ErrNoParent)
+	}
+
+	if err := c.InsertRight(NewBaseNode("L", "")); err != ErrNoParent {
+		t.Fatalf("InsertRight() on the root = %v, want %v", err, ErrNoParent)
+	}
+
+	_ = c.Down(0)
+
+	_ = c.InsertLeft(NewBaseNode("L", ""))
+	_ = c.InsertRight(NewBaseNode("M", ""))
+
+	_ = c.Next()
+	_ = c.Next()
+
+	_ = c.InsertRight(NewBaseNode("N", ""))
+
+	if got := sexprOf(t, root); got != `(R (L) (A) (M) (B) (N))` || c.Node().Type != "B" {
+		t.Fatalf("the insertions produced %s with the cursor on %v", got, c.Node())
+	}
+
+	var bad_param *common.ErrBadParam
+
+	if err := c.InsertLeft(root); !errors.As(err, &bad_param) {
+		t.Fatalf("InsertLeft() with an ancestor = %v, want a *common.ErrBadParam", err)
+	}
+
+	if err := c.InsertRight(nil); !errors.As(err, &bad_param) {
+		t.Fatalf("InsertRight(nil) = %v, want a *common.ErrBadParam", err)
+	}
+
+	// A cursor on a subtree cannot insert siblings of its root.
+	c = NewCursor(root.FirstChild)
+
+	if err := c.InsertRight(NewBaseNode("O", "")); err != ErrNoParent {
+		t.Fatalf("InsertRight() on a subtree root = %v, want %v", err, ErrNoParent)
+	}
+}
+
+// TestCursorDelete checks where the cursor moves after Delete and that the
+// root cannot be deleted.
+func TestCursorDelete(t *testing.T) {
+	root := mustSExpr(t, `(R (A (X)) (B) (C))`)
+	c := NewCursor(root)
+
+	if removed, err := c.Delete(); removed != nil || err != ErrNoParent {
+		t.Fatalf("Delete() on the root = %v, %v, want nil, %v", removed, err, ErrNoParent)
+	}
+
+	steps := []struct {
+		tree string
+		node string
+	}{
+		// The next sibling.
+		{`(R (A (X)) (C))`, "C"},
+		// The previous sibling.
+		{`(R (A (X)))`, "A"},
+	}
+
+	_ = c.Down(1)
+
+	for _, step := range steps {
+		removed, err := c.Delete()
+		if err != nil || removed.Parent != nil {
+			t.Fatalf("Delete() = %v, %v", removed, err)
+		}
+
+		if got := sexprOf(t, root); got != step.tree || c.Node().Type != step.node {
+			t.Fatalf("Delete() produced %s with the cursor on %v, want %s on %s", got, c.Node(), step.tree, step.node)
+		}
+	}
+
+	// The parent.
+	_ = c.Down(0)
+
+	_, _ = c.Delete()
+
+	if got := sexprOf(t, root); got != `(R (A))` || c.Node().Type != "A" || c.Depth() != 1 {
+		t.Fatalf("Delete() produced %s with the cursor on %v at depth %d", got, c.Node(), c.Depth())
+	}
+}
+
+// TestCursorNil checks that a nil cursor reports a nil receiver.
+func TestCursorNil(t *testing.T) {
+	var c *Cursor[string, string]
+
+	if NewCursor[string, string](nil) != nil {
+		t.Fatal("NewCursor(nil) != nil")
+	}
+
+	if c.Node() != nil || c.Root() != nil || c.Path() != nil || c.Depth() != 0 {
+		t.Fatal("the getters of a nil cursor do not return zero values")
+	}
+
+	errs := []error{c.Up(), c.Down(0), c.Next(), c.Prev(), c.Replace(NewBaseNode("A", "")),
+		c.InsertLeft(NewBaseNode("A", "")), c.InsertRight(NewBaseNode("A", ""))}
+
+	_, err := c.Delete()
+	errs = append(errs, err)
+
+	for i, err := range errs {
+		if err != common.ErrNilReceiver {
+			t.Errorf("operation #%d on a nil cursor = %v, want %v", i, err, common.ErrNilReceiver)
+		}
+	}
+}
@@ -0,0 +1,674 @@
+package tree
+
+import (
+	"strconv"
+	"strings"
+
+	common "github.com/PlayerR9/mygo-data/common"
+)
+
+// EditKind is the kind of an edit.
+type EditKind int
+
+const (
+	// EditInsert inserts a new leaf node.
+	EditInsert EditKind = iota
+
+	// EditDelete deletes a node.
+	EditDelete
+
+	// EditMove moves a node, with its subtree, under another parent.
+	EditMove
+
+	// EditRelabel changes the type and the data of a node.
+	EditRelabel
+)
+
+// String implements fmt.Stringer.
+func (k EditKind) String() string {
+	switch k {
+	case EditInsert:
+		return "insert"
+	case EditDelete:
+		return "delete"
+	case EditMove:
+		return "move"
+	case EditRelabel:
+		return "relabel"
+	default:
+		return "EditKind(" + strconv.Itoa(int(k)) + ")"
+	}
+}
+
+// Edit is an operation of an edit script. Paths are paths of child indexes
+// from the root and are resolved against the tree as modified by the previous
+// edits of the script.
+type Edit struct {
+	// Kind is the kind of the edit.
+	Kind EditKind
+
+	// Path is the path of the parent for EditInsert and the path of the node
+	// for the other kinds.
+	Path []int
+
+	// To is the path of the new parent for EditMove. It is resolved after the
+	// node has been detached.
+	To []int
+
+	// Index is the position of the node among its new siblings for EditInsert
+	// and EditMove.
+	Index int
+
+	// Type and Data are the labels of the inserted node for EditInsert, of the
+	// deleted node for EditDelete and the new labels for EditRelabel.
+	Type, Data string
+
+	// OldType and OldData are the previous labels for EditRelabel.
+	OldType, OldData string
+}
+
+// String implements fmt.Stringer.
+//
+// Format:
+//
+//	"insert <node> into <path> at <index>"
+//	"delete <node> at <path>"
+//	"move <path> to <to> at <index>"
+//	"relabel <path>: <old node> -> <node>"
+func (e Edit) String() string {
+	var builder strings.Builder
+
+	node := BaseNode{Type: e.Type, Data: e.Data}
+
+	_, _ = builder.WriteString(e.Kind.String())
+	_, _ = builder.WriteRune(' ')
+
+	switch e.Kind {
+	case EditInsert:
+		_, _ = builder.WriteString(node.String())
+		_, _ = builder.WriteString(" into ")
+		_, _ = builder.WriteString(pathString(e.Path))
+		_, _ = builder.WriteString(" at ")
+		_, _ = builder.WriteString(strconv.Itoa(e.Index))
+	case EditDelete:
+		_, _ = builder.WriteString(node.String())
+		_, _ = builder.WriteString(" at ")
+		_, _ = builder.WriteString(pathString(e.Path))
+	case EditMove:
+		_, _ = builder.WriteString(pathString(e.Path))
+		_, _ = builder.WriteString(" to ")
+		_, _ = builder.WriteString(pathString(e.To))
+		_, _ = builder.WriteString(" at ")
+		_, _ = builder.WriteString(strconv.Itoa(e.Index))
+	case EditRelabel:
+		old := BaseNode{Type: e.OldType, Data: e.OldData}
+
+		_, _ = builder.WriteString(pathString(e.Path))
+		_, _ = builder.WriteString(": ")
+		_, _ = builder.WriteString(old.String())
+		_, _ = builder.WriteString(" -> ")
+		_, _ = builder.WriteString(node.String())
+	}
+
+	str := builder.String()
+	return str
+}
+
+// EditScript is a sequence of edits that transforms a tree into another.
+type EditScript []Edit
+
+// String implements fmt.Stringer. Each edit is written on its own line.
+func (s EditScript) String() string {
+	var builder strings.Builder
+
+	for _, e := range s {
+		_, _ = builder.WriteString(e.String())
+		_, _ = builder.WriteRune('\n')
+	}
+
+	str := builder.String()
+	return str
+}
+
+// differ holds the state of Diff.
+type differ struct {
+	// root is the root of the working copy of the source tree.
+	root *BaseNode
+
+	// fwd maps the nodes of the working copy to the nodes of the target tree.
+	fwd map[*BaseNode]*BaseNode
+
+	// bwd maps the nodes of the target tree to the nodes of the working copy.
+	bwd map[*BaseNode]*BaseNode
+
+	// in_order contains the nodes, of either tree, that are in their final
+	// relative order.
+	in_order map[*BaseNode]bool
+
+	// script is the edit script being built.
+	script EditScript
+}
+
+// link records that the node a of the working copy matches the node b of the
+// target tree.
+//
+// Parameters:
+//   - a: The node of the working copy.
+//   - b: The node of the target tree.
+func (d *differ) link(a, b *BaseNode) {
+	d.fwd[a] = b
+	d.bwd[b] = a
+}
+
+// Diff computes an edit script that transforms the tree rooted at from into the
+// tree rooted at to. Neither tree is modified.
+//
+// Nodes are first matched: identical subtrees are matched top-down, then nodes
+// with the same type are matched bottom-up from their matched children and
+// top-down from their matched parents. The script is then generated from the
+// matching (Chawathe et al.); unmatched target nodes are inserted, matched
+// nodes under a different parent or out of order are moved, matched nodes with
+// different labels are relabeled and unmatched source nodes are deleted. The
+// roots are always matched.
+//
+// Parameters:
+//   - from: The root of the source tree.
+//   - to: The root of the target tree.
+//
+// Returns:
+//   - EditScript: The edit script. Empty if the trees are equal.
+//   - error: An error if a root is nil.
+//
+// Errors:
+//   - common.ErrBadParam: If a root is nil.
+func Diff(from, to *BaseNode) (EditScript, error) {
+	if from == nil {
+		err := common.NewErrNilParam("from")
+		return nil, err
+	} else if to == nil {
+		err := common.NewErrNilParam("to")
+		return nil, err
+	}
+
+	work := Clone(from)
+
+	d := &differ{
+		root:     work,
+		fwd:      make(map[*BaseNode]*BaseNode),
+		bwd:      make(map[*BaseNode]*BaseNode),
+		in_order: make(map[*BaseNode]bool),
+	}
+
+	d.match(work, to)
+
+	for x := range LevelOrder(to) {
+		w, ok := d.bwd[x]
+
+		if !ok {
+			z := d.bwd[x.Parent]
+			k := d.findPos(x)
+
+			w = NewBaseNode(x.Type, x.Data)
+			_ = insertChildAt(z, w, k)
+
+			d.script = append(d.script, Edit{
+				Kind:  EditInsert,
+				Path:  pathOf(work, z),
+				Index: k,
+				Type:  x.Type,
+				Data:  x.Data,
+			})
+
+			d.link(w, x)
+			d.in_order[w] = true
+			d.in_order[x] = true
+		} else {
+			if w.Type != x.Type || w.Data != x.Data {
+				d.script = append(d.script, Edit{
+					Kind:    EditRelabel,
+					Path:    pathOf(work, w),
+					Type:    x.Type,
+					Data:    x.Data,
+					OldType: w.Type,
+					OldData: w.Data,
+				})
+
+				w.Type = x.Type
+				w.Data = x.Data
+			}
+
+			if x != to && w.Parent != d.bwd[x.Parent] {
+				d.move(w, x)
+			}
+		}
+
+		d.alignChildren(w, x)
+	}
+
+	var deleted []*BaseNode
+
+	for n := range PostOrder(work) {
+		if _, ok := d.fwd[n]; !ok {
+			deleted = append(deleted, n)
+		}
+	}
+
+	for _, n := range deleted {
+		d.script = append(d.script, Edit{
+			Kind: EditDelete,
+			Path: pathOf(work, n),
+			Type: n.Type,
+			Data: n.Data,
+		})
+
+		_ = n.Detach()
+	}
+
+	return d.script, nil
+}
+
+// move moves the node w of the working copy so that it has the same position
+// as its partner x in the target tree.
+//
+// Parameters:
+//   - w: The node of the working copy to move.
+//   - x: The partner of w in the target tree.
+func (d *differ) move(w, x *BaseNode) {
+	path := pathOf(d.root, w)
+
+	_ = w.Detach()
+
+	z := d.bwd[x.Parent]
+	k := d.findPos(x)
+
+	_ = insertChildAt(z, w, k)
+
+	d.script = append(d.script, Edit{
+		Kind:  EditMove,
+		Path:  path,
+		To:    pathOf(d.root, z),
+		Index: k,
+	})
+
+	d.in_order[w] = true
+	d.in_order[x] = true
+}
+
+// findPos returns the position, among the children of the partner of its
+// parent, at which the partner of the target node x must be placed: right
+// after the partner of the rightmost left sibling of x that is in order.
+//
+// Parameters:
+//   - x: The node of the target tree.
+//
+// Returns:
+//   - int: The position, starting from 0.
+func (d *differ) findPos(x *BaseNode) int {
+	for v := x.PrevSibling; v != nil; v = v.PrevSibling {
+		if !d.in_order[v] {
+			continue
+		}
+
+		idx := siblingIndex(d.bwd[v]) + 1
+		return idx
+	}
+
+	return 0
+}
+
+// alignChildren moves the children of w so that the ones matched with children
+// of x are in the same relative order. The longest common subsequence of
+// matched children is kept in place.
+//
+// Parameters:
+//   - w: The node of the working copy.
+//   - x: The partner of w in the target tree.
+func (d *differ) alignChildren(w, x *BaseNode) {
+	var s1, s2 []*BaseNode
+
+	for c := w.FirstChild; c != nil; c = c.NextSibling {
+		d.in_order[c] = false
+
+		if p, ok := d.fwd[c]; ok && p.Parent == x {
+			s1 = append(s1, c)
+		}
+	}
+
+	for c := x.FirstChild; c != nil; c = c.NextSibling {
+		d.in_order[c] = false
+
+		if p, ok := d.bwd[c]; ok && p.Parent == w {
+			s2 = append(s2, c)
+		}
+	}
+
+	if len(s1) == 0 || len(s2) == 0 {
+		return
+	}
+
+	// lengths[i][j] is the length of the LCS of s1[i:] and s2[j:].
+	lengths := make([][]int, len(s1)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(s2)+1)
+	}
+
+	for i := len(s1) - 1; i >= 0; i-- {
+		for j := len(s2) - 1; j >= 0; j-- {
+			if d.fwd[s1[i]] == s2[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	for i, j := 0, 0; i < len(s1) && j < len(s2); {
+		switch {
+		case d.fwd[s1[i]] == s2[j]:
+			d.in_order[s1[i]] = true
+			d.in_order[s2[j]] = true
+
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	for _, b := range s2 {
+		if !d.in_order[b] {
+			d.move(d.bwd[b], b)
+		}
+	}
+}
+
+// match computes the matching between the working copy and the target tree.
+//
+// Parameters:
+//   - work: The root of the working copy.
+//   - to: The root of the target tree.
+func (d *differ) match(work, to *BaseNode) {
+	d.link(work, to)
+
+	// 1. Identical subtrees, top-down.
+
+	hashes1 := SubtreeHashes(work)
+	hashes2 := SubtreeHashes(to)
+
+	candidates := make(map[uint64][]*BaseNode)
+
+	for n := range PreOrder(work) {
+		if n != work {
+			h := hashes1[n]
+			candidates[h] = append(candidates[h], n)
+		}
+	}
+
+	stack := to.Children()
+
+	for len(stack) > 0 {
+		x := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if d.matchIdentical(candidates[hashes2[x]], x) {
+			continue
+		}
+
+		for c := x.LastChild; c != nil; c = c.PrevSibling {
+			stack = append(stack, c)
+		}
+	}
+
+	// 2. Bottom-up, from the partners of the children.
+
+	for x := range PostOrder(to) {
+		if _, ok := d.bwd[x]; ok {
+			continue
+		}
+
+		votes := make(map[*BaseNode]int)
+
+		var best *BaseNode
+
+		for c := x.FirstChild; c != nil; c = c.NextSibling {
+			pc, ok := d.bwd[c]
+			if !ok || pc.Parent == nil {
+				continue
+			}
+
+			cand := pc.Parent
+			if _, ok := d.fwd[cand]; ok || cand.Type != x.Type {
+				continue
+			}
+
+			votes[cand]++
+
+			if best == nil || votes[cand] > votes[best] {
+				best = cand
+			}
+		}
+
+		if best != nil {
+			d.link(best, x)
+		}
+	}
+
+	// 3. Top-down, from the partner of the parent.
+
+	for x := range PreOrder(to) {
+		if _, ok := d.bwd[x]; ok || x.Parent == nil {
+			continue
+		}
+
+		p, ok := d.bwd[x.Parent]
+		if !ok {
+			continue
+		}
+
+		var best *BaseNode
+
+		for c := p.FirstChild; c != nil; c = c.NextSibling {
+			if _, ok := d.fwd[c]; ok || c.Type != x.Type {
+				continue
+			}
+
+			if c.Data == x.Data {
+				best = c
+				break
+			} else if best == nil {
+				best = c
+			}
+		}
+
+		if best != nil {
+			d.link(best, x)
+		}
+	}
+}
+
+// matchIdentical matches the subtree rooted at x with one of the identical
+// candidate subtrees of the working copy. Candidates that are already in the
+// right place are preferred, so that equal trees are matched node for node and
+// yield an empty script: first the one at the same index under the partner of
+// the parent of x, then any child of that partner, then any other one.
+//
+// Parameters:
+//   - candidates: The subtrees of the working copy with the same hash as x.
+//   - x: The root of the subtree of the target tree.
+//
+// Returns:
+//   - bool: True if the subtree was matched, false otherwise.
+func (d *differ) matchIdentical(candidates []*BaseNode, x *BaseNode) bool {
+	if len(candidates) == 0 {
+		return false
+	}
+
+	p := d.bwd[x.Parent]
+	idx := siblingIndex(x)
+
+	rank := func(a *BaseNode) int {
+		if p == nil || a.Parent != p {
+			return 2
+		} else if siblingIndex(a) != idx {
+			return 1
+		}
+
+		return 0
+	}
+
+	for r := range 3 {
+		for _, a := range candidates {
+			if rank(a) == r && d.tryMatchSubtree(a, x) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// tryMatchSubtree matches the subtree rooted at a with the subtree rooted at b
+// if they are identical and no node of the subtree rooted at a is matched yet.
+//
+// Parameters:
+//   - a: The root of the subtree of the working copy.
+//   - b: The root of the subtree of the target tree.
+//
+// Returns:
+//   - bool: True if the subtrees were matched, false otherwise.
+func (d *differ) tryMatchSubtree(a, b *BaseNode) bool {
+	var pairs [][2]*BaseNode
+
+	stack := [][2]*BaseNode{{a, b}}
+
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		x, y := top[0], top[1]
+
+		if _, ok := d.fwd[x]; ok || x.Type != y.Type || x.Data != y.Data {
+			return false
+		}
+
+		pairs = append(pairs, top)
+
+		cx, cy := x.FirstChild, y.FirstChild
+
+		for cx != nil && cy != nil {
+			stack = append(stack, [2]*BaseNode{cx, cy})
+
+			cx, cy = cx.NextSibling, cy.NextSibling
+		}
+
+		if cx != nil || cy != nil {
+			return false
+		}
+	}
+
+	for _, pair := range pairs {
+		d.link(pair[0], pair[1])
+	}
+
+	return true
+}
+
+// Apply replays the edit script on the tree rooted at root, modifying it in
+// place. Applying the script returned by Diff(from, to) to from makes it equal
+// to to. If an edit fails, the tree is left with the previous edits applied
+// and without the failing one.
+//
+// Parameters:
+//   - root: The root of the tree to modify.
+//   - script: The edit script to apply.
+//
+// Returns:
+//   - error: An error if the root is nil or if an edit could not be applied.
+//
+// Errors:
+//   - common.ErrBadParam: If the root is nil or if an edit refers to a path that
+//     does not exist, to an out of range index or tries to delete or move the
+//     root.
+func Apply(root *BaseNode, script EditScript) error {
+	if root == nil {
+		err := common.NewErrNilParam("root")
+		return err
+	}
+
+	for i, e := range script {
+		node := nodeAt(root, e.Path)
+		if node == nil {
+			err := common.NewErrBadParam("script", "edit #"+strconv.Itoa(i)+" refers to the missing path "+pathString(e.Path))
+			return err
+		}
+
+		switch e.Kind {
+		case EditInsert:
+			err := insertChildAt(node, NewBaseNode(e.Type, e.Data), e.Index)
+			if err != nil {
+				err := common.NewErrBadParam("script", "edit #"+strconv.Itoa(i)+" has an out of range index")
+				return err
+			}
+		case EditDelete:
+			if node == root {
+				err := common.NewErrBadParam("script", "edit #"+strconv.Itoa(i)+" deletes the root")
+				return err
+			}
+
+			_ = node.Detach()
+		case EditMove:
+			if node == root {
+				err := common.NewErrBadParam("script", "edit #"+strconv.Itoa(i)+" moves the root")
+				return err
+			}
+
+			// To is resolved after the detach, so the node is put back where it
+			// was if the move fails.
+			old_parent, old_prev := node.Parent, node.PrevSibling
+
+			_ = node.Detach()
+
+			parent := nodeAt(root, e.To)
+			if parent == nil {
+				restoreChild(old_parent, old_prev, node)
+
+				err := common.NewErrBadParam("script", "edit #"+strconv.Itoa(i)+" refers to the missing path "+pathString(e.To))
+				return err
+			}
+
+			err := insertChildAt(parent, node, e.Index)
+			if err != nil {
+				restoreChild(old_parent, old_prev, node)
+
+				err := common.NewErrBadParam("script", "edit #"+strconv.Itoa(i)+" has an out of range index")
+				return err
+			}
+		case EditRelabel:
+			node.Type = e.Type
+			node.Data = e.Data
+		default:
+			err := common.NewErrBadParam("script", "edit #"+strconv.Itoa(i)+" has an unknown kind")
+			return err
+		}
+	}
+
+	return nil
+}
+
+// restoreChild puts a detached node back under its former parent, right after
+// its former previous sibling.
+//
+// Parameters:
+//   - parent: The former parent of the node. Assumed to be non-nil.
+//   - prev: The former previous sibling of the node, or nil if it was the first
+//     child.
+//   - node: The detached node. Assumed to be non-nil.
+func restoreChild(parent, prev, node *BaseNode) {
+	if prev == nil {
+		_ = parent.PrependChild(node)
+	} else {
+		_ = prev.InsertAfter(node)
+	}
+}
@@ -0,0 +1,179 @@
+package tree
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// randomTree builds a random tree of the given size whose types and data are
+// drawn from small alphabets, so that random trees share labels and subtrees.
+//
+// Parameters:
+//   - rng: The source of randomness.
+//   - size: The number of nodes. Assumed to be positive.
+//
+// Returns:
+//   - *BaseNode: The root of the tree. Never returns nil.
+func randomTree(rng *rand.Rand, size int) *BaseNode {
+	types := []string{"A", "B", "C", "D"}
+	datas := []string{"", "x", "y"}
+
+	label := func() *BaseNode {
+		n := NewBaseNode(types[rng.IntN(len(types))], datas[rng.IntN(len(datas))])
+		return n
+	}
+
+	nodes := []*BaseNode{label()}
+
+	for len(nodes) < size {
+		n := label()
+
+		_ = nodes[rng.IntN(len(nodes))].AppendChild(n)
+
+		nodes = append(nodes, n)
+	}
+
+	return nodes[0]
+}
+
+// mutateTree applies a few random relabels, moves, insertions and deletions to
+// the tree rooted at root, which is never removed.
+//
+// Parameters:
+//   - rng: The source of randomness.
+//   - root: The root of the tree to modify.
+//
+// Returns:
+//   - *BaseNode: The root of the tree. Never returns nil.
+func mutateTree(rng *rand.Rand, root *BaseNode) *BaseNode {
+	for range 1 + rng.IntN(4) {
+		nodes := slices.Collect(PreOrder(root))
+		n := nodes[rng.IntN(len(nodes))]
+
+		switch rng.IntN(4) {
+		case 0:
+			n.Data += "'"
+		case 1:
+			target := nodes[rng.IntN(len(nodes))]
+			if n != root && !isAncestorOf(n, target) {
+				_ = target.AppendChild(n)
+			}
+		case 2:
+			_ = n.AppendChild(randomTree(rng, 1+rng.IntN(3)))
+		case 3:
+			if n != root {
+				_ = n.Detach()
+			}
+		}
+	}
+
+	return root
+}
+
+// TestDiffApply checks on random tree pairs that applying the script computed
+// by Diff turns the source tree into the target tree, that the result is a
+// valid tree and that Diff leaves the source tree untouched.
+func TestDiffApply(t *testing.T) {
+	rng := rand.New(rand.NewPCG(9, 9))
+
+	for i := range 2000 {
+		a := randomTree(rng, 1+rng.IntN(20))
+
+		var b *BaseNode
+
+		if i%2 == 0 {
+			b = randomTree(rng, 1+rng.IntN(20))
+		} else {
+			b = mutateTree(rng, Clone(a))
+		}
+
+		original := Clone(a)
+
+		script, err := Diff(a, b)
+		if err != nil {
+			t.Fatalf("#%d: Diff returned %v", i, err)
+		}
+
+		if !Equal(a, original) {
+			t.Fatalf("#%d: Diff modified its source tree", i)
+		}
+
+		got := Clone(a)
+
+		err = Apply(got, script)
+		if err != nil {
+			t.Fatalf("#%d: Apply returned %v\nscript: %v", i, err, script)
+		}
+
+		err = Validate(got)
+		if err != nil {
+			t.Fatalf("#%d: Apply produced an invalid tree: %v", i, err)
+		}
+
+		if !Equal(got, b) {
+			t.Fatalf("#%d: Apply(Clone(a), Diff(a, b)) differs from b\nscript: %v", i, script)
+		}
+	}
+}
+
+// TestDiffEqualTrees checks that equal trees yield an empty script.
+func TestDiffEqualTrees(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+
+	for i := range 200 {
+		a := randomTree(rng, 1+rng.IntN(20))
+
+		script, err := Diff(a, Clone(a))
+		if err != nil {
+			t.Fatalf("#%d: Diff returned %v", i, err)
+		}
+
+		if len(script) != 0 {
+			t.Fatalf("#%d: Diff of equal trees returned %v", i, script)
+		}
+	}
+}
+
+// TestApplyFailure checks that a failing edit is reported and leaves the tree
+// with the previous edits applied and without the failing one.
+func TestApplyFailure(t *testing.T) {
+	rename := Edit{Kind: EditRelabel, Path: []int{1}, Type: "C"}
+
+	tests := []struct {
+		name string
+		edit Edit
+	}{
+		{"MissingPath", Edit{Kind: EditRelabel, Path: []int{5}, Type: "Z"}},
+		{"InsertOutOfRange", Edit{Kind: EditInsert, Path: []int{}, Type: "Z", Index: 7}},
+		{"DeleteRoot", Edit{Kind: EditDelete, Path: []int{}}},
+		{"MoveRoot", Edit{Kind: EditMove, Path: []int{}, To: []int{0}}},
+		{"MoveMissingParent", Edit{Kind: EditMove, Path: []int{0}, To: []int{7}}},
+		{"MoveOutOfRange", Edit{Kind: EditMove, Path: []int{0}, To: []int{}, Index: 7}},
+		{"MoveLastOutOfRange", Edit{Kind: EditMove, Path: []int{1}, To: []int{0}, Index: 5}},
+		{"MoveIntoItself", Edit{Kind: EditMove, Path: []int{0}, To: []int{0, 0}}},
+		{"UnknownKind", Edit{Kind: EditKind(99), Path: []int{}}},
+	}
+
+	for _, tt := range tests {
+		root := mustSExpr(t, `(R (A (X)) (B))`)
+
+		err := Apply(root, EditScript{rename, tt.edit})
+		if err == nil {
+			t.Errorf("%s: Apply() succeeded", tt.name)
+			continue
+		}
+
+		if got, want := sexprOf(t, root), `(R (A (X)) (C))`; got != want {
+			t.Errorf("%s: Apply() left %s, want %s", tt.name, got, want)
+		}
+
+		if err := Validate(root); err != nil {
+			t.Errorf("%s: Apply() left an invalid tree: %v", tt.name, err)
+		}
+	}
+
+	if Apply(nil, nil) == nil {
+		t.Error("Apply() on a nil root succeeded")
+	}
+}
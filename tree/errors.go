@@ -0,0 +1,146 @@
+package tree
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrNoParent occurs when an operation requires a node to have a parent
+	// but it does not. This error can be checked with the == operator.
+	//
+	// Format:
+	// 	"node has no parent"
+	ErrNoParent error
+
+	// ErrNodeDeleted occurs when an operation is attempted on the current node
+	// of a walk after it was deleted. This error can be checked with the ==
+	// operator.
+	//
+	// Format:
+	// 	"current node was deleted"
+	ErrNodeDeleted error
+
+	// ErrNoFixpoint occurs when rewrite rules still apply after the maximum
+	// number of passes. This error can be checked with the == operator.
+	//
+	// Format:
+	// 	"rewrite rules did not reach a fixed point"
+	ErrNoFixpoint error
+
+	// ErrNoChild occurs when a cursor is moved down to a child that does not
+	// exist. This error can be checked with the == operator.
+	//
+	// Format:
+	// 	"node has no such child"
+	ErrNoChild error
+
+	// ErrNoSibling occurs when a cursor is moved to a sibling that does not
+	// exist. This error can be checked with the == operator.
+	//
+	// Format:
+	// 	"node has no such sibling"
+	ErrNoSibling error
+
+	// ErrUnreducedStack occurs when a tree is built while more than one node
+	// is left on the stack of a Builder. This error can be checked with the
+	// == operator.
+	//
+	// Format:
+	// 	"more than one node is left on the stack"
+	ErrUnreducedStack error
+
+	// ErrTreeFull occurs when a node is added to an IndexTree that already
+	// holds as many nodes as a NodeID can identify. This error can be checked
+	// with the == operator.
+	//
+	// Format:
+	// 	"index tree is full"
+	ErrTreeFull error
+
+	// ErrBrokenBuilder occurs when a Builder is used after a failed operation
+	// could not restore its stack. This error can be checked with the ==
+	// operator.
+	//
+	// Format:
+	// 	"builder is broken: a failed operation could not be undone"
+	ErrBrokenBuilder error
+)
+
+func init() {
+	ErrNoParent = errors.New("node has no parent")
+	ErrNodeDeleted = errors.New("current node was deleted")
+	ErrNoFixpoint = errors.New("rewrite rules did not reach a fixed point")
+	ErrNoChild = errors.New("node has no such child")
+	ErrNoSibling = errors.New("node has no such sibling")
+	ErrUnreducedStack = errors.New("more than one node is left on the stack")
+	ErrTreeFull = errors.New("index tree is full")
+	ErrBrokenBuilder = errors.New("builder is broken: a failed operation could not be undone")
+}
+
+// ErrSyntax occurs when a textual representation could not be parsed.
+type ErrSyntax struct {
+	// Format is the name of the format being parsed.
+	Format string
+
+	// Offset is the byte offset in the input at which the error occurred.
+	Offset int
+
+	// Reason is the reason the input is not valid.
+	Reason string
+}
+
+// Error implements error.
+func (e ErrSyntax) Error() string {
+	var reason string
+
+	if e.Reason == "" {
+		reason = "invalid syntax"
+	} else {
+		reason = e.Reason
+	}
+
+	var builder strings.Builder
+
+	if e.Format != "" {
+		_, _ = builder.WriteString(e.Format)
+		_, _ = builder.WriteRune(' ')
+	}
+
+	_, _ = builder.WriteString("input at offset ")
+	_, _ = builder.WriteString(strconv.Itoa(e.Offset))
+	_, _ = builder.WriteString(": ")
+	_, _ = builder.WriteString(reason)
+
+	str := builder.String()
+	return str
+}
+
+// NewErrSyntax returns an error with the given format, offset and reason.
+//
+// Parameters:
+//   - format: The name of the format being parsed.
+//   - offset: The byte offset in the input at which the error occurred.
+//   - reason: The reason the input is not valid.
+//
+// Returns:
+//   - error: An instance of ErrSyntax. Never returns nil.
+//
+// Format:
+//
+//	"<format> input at offset <offset>: <reason>"
+//
+// Where:
+//   - <format> is the name of the format. If empty, it is ignored.
+//   - <offset> is the byte offset in the input.
+//   - <reason> is the reason the input is not valid. If empty, "invalid syntax" is used.
+func NewErrSyntax(format string, offset int, reason string) error {
+	e := &ErrSyntax{
+		Format: format,
+		Offset: offset,
+		Reason: reason,
+	}
+
+	return e
+}
@@ -0,0 +1,516 @@
+package tree
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	common "github.com/PlayerR9/mygo-data/common"
+)
+
+// GenericNode is a node in the tree whose type and data are generic.
+//
+// The type is usually an enum-like comparable value (e.g. a token kind) and the
+// data can be any payload.
+type GenericNode[T comparable, D any] struct {
+	// Parent, NextSibling, PrevSibling, FirstChild, and LastChild are pointers to
+	// other nodes in the tree.
+	Parent, NextSibling, PrevSibling, FirstChild, LastChild *GenericNode[T, D]
+
+	// Type is the type of the node.
+	Type T
+
+	// Data is the data associated with the node.
+	Data D
+
+	// Span is the range of the source input the node was parsed from. Nil if
+	// unknown.
+	Span *Span
+}
+
+// String implements Node.
+//
+// Format:
+//
+//	"Node[<type> (<data>)]"
+//
+// Where:
+//   - <type> is the type of the node.
+//   - (<data>) is the data of the node. It is omitted if the data is nil or
+//     an empty string. String data is quoted.
+func (n GenericNode[T, D]) String() string {
+	str := nodeString(n.Type, n.Data)
+	return str
+}
+
+// nodeString returns the string representation of a node with the given type
+// and data, as described in GenericNode.String.
+//
+// Parameters:
+//   - type_: The type of the node.
+//   - data: The data of the node.
+//
+// Returns:
+//   - string: The string representation of the node.
+func nodeString(type_, data any) string {
+	var builder strings.Builder
+
+	_, _ = builder.WriteString("Node[")
+	_, _ = builder.WriteString(fmt.Sprint(type_))
+
+	str, ok := dataString(data)
+	if ok {
+		_, _ = builder.WriteString(" (")
+		_, _ = builder.WriteString(str)
+		_, _ = builder.WriteRune(')')
+	}
+
+	_, _ = builder.WriteRune(']')
+
+	return builder.String()
+}
+
+// dataString returns the string representation of the given data.
+//
+// Parameters:
+//   - data: The data to stringify.
+//
+// Returns:
+//   - string: The string representation of the data. Strings are quoted.
+//   - bool: False if the data is nil or an empty string, true otherwise.
+func dataString(data any) (string, bool) {
+	switch data := data.(type) {
+	case nil:
+		return "", false
+	case string:
+		if data == "" {
+			return "", false
+		}
+
+		return strconv.Quote(data), true
+	default:
+		return fmt.Sprint(data), true
+	}
+}
+
+// NewGenericNode creates a new GenericNode with the specified type and data.
+//
+// Parameters:
+//   - type_: The type of the node.
+//   - data: The data associated with the node.
+//
+// Returns:
+//   - *GenericNode[T, D]: A pointer to the newly created node. Never returns nil.
+func NewGenericNode[T comparable, D any](type_ T, data D) *GenericNode[T, D] {
+	n := &GenericNode[T, D]{
+		Type: type_,
+		Data: data,
+	}
+
+	return n
+}
+
+// PrependChild prepends the given child to the node's children. If the child
+// already has a parent, it is detached first.
+//
+// Parameters:
+//   - child: The child to be prepended.
+//
+// Returns:
+//   - error: An error if the receiver is nil or if the child is the receiver
+//     or one of its ancestors.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If the child is the receiver or one of its ancestors.
+//   - *ErrInvariant: If the debug mode is enabled and a modified tree is
+//     inconsistent. See SetDebug.
+func (n *GenericNode[T, D]) PrependChild(child *GenericNode[T, D]) error {
+	if n == nil {
+		return common.ErrNilReceiver
+	}
+
+	if child == nil {
+		return nil
+	}
+
+	if isAncestorOf(child, n) {
+		err := common.NewErrBadParam("child", "must not be the receiver or one of its ancestors")
+		return err
+	}
+
+	old_parent := child.Parent
+
+	child.unlink()
+
+	child.Parent = n
+
+	if n.FirstChild == nil {
+		n.LastChild = child
+	} else {
+		child.NextSibling = n.FirstChild
+		n.FirstChild.PrevSibling = child
+	}
+
+	n.FirstChild = child
+
+	err := debugValidate(n, old_parent)
+	return err
+}
+
+// AppendChild appends the given child to the node's children. If the child
+// already has a parent, it is detached first.
+//
+// Parameters:
+//   - child: The child to be appended.
+//
+// Returns:
+//   - error: An error if the receiver is nil or if the child is the receiver
+//     or one of its ancestors.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If the child is the receiver or one of its ancestors.
+//   - *ErrInvariant: If the debug mode is enabled and a modified tree is
+//     inconsistent. See SetDebug.
+func (n *GenericNode[T, D]) AppendChild(child *GenericNode[T, D]) error {
+	if n == nil {
+		return common.ErrNilReceiver
+	}
+
+	if child == nil {
+		return nil
+	}
+
+	if isAncestorOf(child, n) {
+		err := common.NewErrBadParam("child", "must not be the receiver or one of its ancestors")
+		return err
+	}
+
+	old_parent := child.Parent
+
+	child.unlink()
+
+	child.Parent = n
+
+	if n.LastChild == nil {
+		n.FirstChild = child
+	} else {
+		n.LastChild.NextSibling = child
+		child.PrevSibling = n.LastChild
+	}
+
+	n.LastChild = child
+
+	err := debugValidate(n, old_parent)
+	return err
+}
+
+// isAncestorOf checks whether the given node is the target or one of its ancestors.
+//
+// Parameters:
+//   - node: The node to look for.
+//   - target: The node whose ancestors are walked.
+//
+// Returns:
+//   - bool: True if the node is the target or one of its ancestors, false otherwise.
+func isAncestorOf[T comparable, D any](node, target *GenericNode[T, D]) bool {
+	if node.FirstChild == nil {
+		// A leaf can only be an ancestor of itself.
+		return node == target
+	}
+
+	for n := target; n != nil; n = n.Parent {
+		if n == node {
+			return true
+		}
+	}
+
+	return false
+}
+
+// unlink removes the node from its parent and siblings. The node keeps its
+// children.
+func (n *GenericNode[T, D]) unlink() {
+	if n.PrevSibling != nil {
+		n.PrevSibling.NextSibling = n.NextSibling
+	} else if n.Parent != nil && n.Parent.FirstChild == n {
+		n.Parent.FirstChild = n.NextSibling
+	}
+
+	if n.NextSibling != nil {
+		n.NextSibling.PrevSibling = n.PrevSibling
+	} else if n.Parent != nil && n.Parent.LastChild == n {
+		n.Parent.LastChild = n.PrevSibling
+	}
+
+	n.Parent = nil
+	n.PrevSibling = nil
+	n.NextSibling = nil
+}
+
+// Detach removes the node from its parent and siblings, making it the root of
+// its own subtree. The node keeps its children.
+//
+// Returns:
+//   - error: An error if the receiver is nil.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - *ErrInvariant: If the debug mode is enabled and a modified tree is
+//     inconsistent. See SetDebug.
+func (n *GenericNode[T, D]) Detach() error {
+	if n == nil {
+		return common.ErrNilReceiver
+	}
+
+	old_parent := n.Parent
+
+	n.unlink()
+
+	err := debugValidate(n, old_parent)
+	return err
+}
+
+// InsertBefore inserts the given node as the previous sibling of the receiver.
+// If the node already has a parent, it is detached first.
+//
+// Parameters:
+//   - node: The node to insert.
+//
+// Returns:
+//   - error: An error if the node could not be inserted.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - ErrNoParent: If the receiver has no parent.
+//   - common.ErrBadParam: If the node is the receiver or one of its ancestors.
+//   - *ErrInvariant: If the debug mode is enabled and a modified tree is
+//     inconsistent. See SetDebug.
+func (n *GenericNode[T, D]) InsertBefore(node *GenericNode[T, D]) error {
+	if n == nil {
+		return common.ErrNilReceiver
+	} else if n.Parent == nil {
+		return ErrNoParent
+	}
+
+	if node == nil {
+		return nil
+	}
+
+	if isAncestorOf(node, n) {
+		err := common.NewErrBadParam("node", "must not be the receiver or one of its ancestors")
+		return err
+	}
+
+	old_parent := node.Parent
+
+	node.unlink()
+
+	node.Parent = n.Parent
+	node.PrevSibling = n.PrevSibling
+	node.NextSibling = n
+
+	if n.PrevSibling == nil {
+		n.Parent.FirstChild = node
+	} else {
+		n.PrevSibling.NextSibling = node
+	}
+
+	n.PrevSibling = node
+
+	err := debugValidate(n, old_parent)
+	return err
+}
+
+// InsertAfter inserts the given node as the next sibling of the receiver.
+// If the node already has a parent, it is detached first.
+//
+// Parameters:
+//   - node: The node to insert.
+//
+// Returns:
+//   - error: An error if the node could not be inserted.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - ErrNoParent: If the receiver has no parent.
+//   - common.ErrBadParam: If the node is the receiver or one of its ancestors.
+//   - *ErrInvariant: If the debug mode is enabled and a modified tree is
+//     inconsistent. See SetDebug.
+func (n *GenericNode[T, D]) InsertAfter(node *GenericNode[T, D]) error {
+	if n == nil {
+		return common.ErrNilReceiver
+	} else if n.Parent == nil {
+		return ErrNoParent
+	}
+
+	if node == nil {
+		return nil
+	}
+
+	if isAncestorOf(node, n) {
+		err := common.NewErrBadParam("node", "must not be the receiver or one of its ancestors")
+		return err
+	}
+
+	old_parent := node.Parent
+
+	node.unlink()
+
+	node.Parent = n.Parent
+	node.PrevSibling = n
+	node.NextSibling = n.NextSibling
+
+	if n.NextSibling == nil {
+		n.Parent.LastChild = node
+	} else {
+		n.NextSibling.PrevSibling = node
+	}
+
+	n.NextSibling = node
+
+	err := debugValidate(n, old_parent)
+	return err
+}
+
+// ReplaceWith replaces the receiver with the given node in the receiver's
+// parent. The receiver is detached and keeps its children. If the node already
+// has a parent, it is detached first.
+//
+// Parameters:
+//   - node: The node that takes the receiver's place. If nil, the receiver is
+//     simply detached.
+//
+// Returns:
+//   - error: An error if the receiver could not be replaced.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - ErrNoParent: If the receiver has no parent.
+//   - common.ErrBadParam: If the node is an ancestor of the receiver.
+//   - *ErrInvariant: If the debug mode is enabled and a modified tree is
+//     inconsistent. See SetDebug.
+func (n *GenericNode[T, D]) ReplaceWith(node *GenericNode[T, D]) error {
+	if n == nil {
+		return common.ErrNilReceiver
+	} else if n.Parent == nil {
+		return ErrNoParent
+	}
+
+	if node == n {
+		return nil
+	}
+
+	if node != nil {
+		err := n.InsertAfter(node)
+		if err != nil {
+			return err
+		}
+	}
+
+	parent := n.Parent
+
+	n.unlink()
+
+	err := debugValidate(parent, n)
+	return err
+}
+
+// RemoveChild removes the given child from the receiver's children. The child
+// is detached and keeps its own children.
+//
+// Parameters:
+//   - child: The child to remove.
+//
+// Returns:
+//   - error: An error if the child could not be removed.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If the child is nil or is not a child of the receiver.
+//   - *ErrInvariant: If the debug mode is enabled and a modified tree is
+//     inconsistent. See SetDebug.
+func (n *GenericNode[T, D]) RemoveChild(child *GenericNode[T, D]) error {
+	if n == nil {
+		return common.ErrNilReceiver
+	}
+
+	if child == nil {
+		err := common.NewErrNilParam("child")
+		return err
+	} else if child.Parent != n {
+		err := common.NewErrBadParam("child", "is not a child of the receiver")
+		return err
+	}
+
+	child.unlink()
+
+	err := debugValidate(n, child)
+	return err
+}
+
+// RemoveChildren removes all the children of the receiver. Each removed child
+// is detached and keeps its own children.
+//
+// Returns:
+//   - []*GenericNode[T, D]: The removed children, in order. Nil if the receiver
+//     had no children.
+//   - error: An error if the receiver is nil.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - *ErrInvariant: If the debug mode is enabled and a modified tree is
+//     inconsistent. See SetDebug.
+func (n *GenericNode[T, D]) RemoveChildren() ([]*GenericNode[T, D], error) {
+	if n == nil {
+		return nil, common.ErrNilReceiver
+	}
+
+	if n.FirstChild == nil {
+		return nil, nil
+	}
+
+	var children []*GenericNode[T, D]
+
+	for child := n.FirstChild; child != nil; {
+		next := child.NextSibling
+
+		child.Parent = nil
+		child.PrevSibling = nil
+		child.NextSibling = nil
+
+		children = append(children, child)
+
+		child = next
+	}
+
+	n.FirstChild = nil
+	n.LastChild = nil
+
+	err := debugValidate(append([]*GenericNode[T, D]{n}, children...)...)
+	if err != nil {
+		return children, err
+	}
+
+	return children, nil
+}
+
+// Children returns a slice of pointers to the node's children.
+//
+// Returns:
+//   - []*GenericNode[T, D]: A slice containing pointers to the children of the node.
+//     If the node has no children, returns nil.
+func (n GenericNode[T, D]) Children() []*GenericNode[T, D] {
+	if n.FirstChild == nil {
+		return nil
+	}
+
+	var children []*GenericNode[T, D]
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		children = append(children, child)
+	}
+
+	return children
+}
@@ -0,0 +1,236 @@
+package tree
+
+import (
+	"errors"
+	"testing"
+
+	common "github.com/PlayerR9/mygo-data/common"
+)
+
+// TestGenericNode checks AppendChildren, PrependChildren and TreeToString on
+// a GenericNode whose type and data are not strings.
+func TestGenericNode(t *testing.T) {
+	root := NewGenericNode[int, any](1, nil)
+
+	err := AppendChildren(root, []*GenericNode[int, any]{
+		NewGenericNode[int, any](2, 42),
+		nil,
+		NewGenericNode[int, any](3, "x"),
+	})
+	if err != nil {
+		t.Fatalf("AppendChildren returned %v", err)
+	}
+
+	err = PrependChildren(root, []*GenericNode[int, any]{
+		NewGenericNode[int, any](0, 1.5),
+		NewGenericNode[int, any](4, ""),
+	})
+	if err != nil {
+		t.Fatalf("PrependChildren returned %v", err)
+	}
+
+	_ = AppendChildren(root.LastChild, []*GenericNode[int, any]{
+		NewGenericNode[int, any](5, []int{1, 2}),
+	})
+
+	want := "Node[1]\n" +
+		"   Node[0 (1.5)]\n" +
+		"   Node[4]\n" +
+		"   Node[2 (42)]\n" +
+		"   Node[3 (\"x\")]\n" +
+		"      Node[5 ([1 2])]\n"
+
+	if got := TreeToString(root); got != want {
+		t.Fatalf("TreeToString() =\n%s\nwant\n%s", got, want)
+	}
+
+	if err := Validate(root); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+}
+
+// TestGenericNodeAncestor checks that a node cannot become a child of one of
+// its descendants.
+func TestGenericNodeAncestor(t *testing.T) {
+	root := NewGenericNode[int, any](1, nil)
+	child := NewGenericNode[int, any](2, nil)
+
+	_ = root.AppendChild(child)
+
+	var bad *common.ErrBadParam
+
+	err := AppendChildren(child, []*GenericNode[int, any]{root})
+	if !errors.As(err, &bad) {
+		t.Fatalf("AppendChildren() of an ancestor = %v, want a *common.ErrBadParam", err)
+	}
+
+	err = PrependChildren(child, []*GenericNode[int, any]{child})
+	if !errors.As(err, &bad) {
+		t.Fatalf("PrependChildren() of the parent itself = %v, want a *common.ErrBadParam", err)
+	}
+
+	err = AppendChildren[int, any](nil, nil)
+	if !errors.As(err, &bad) {
+		t.Fatalf("AppendChildren() on a nil parent = %v, want a *common.ErrBadParam", err)
+	}
+}
+
+// checkChildren checks that the children of the parent are exactly the given
+// nodes and that every link between them is consistent.
+//
+// Parameters:
+//   - t: The test.
+//   - parent: The parent whose children are checked.
+//   - want: The expected children, in order.
+func checkChildren(t *testing.T, parent *BaseNode, want ...*BaseNode) {
+	t.Helper()
+
+	var got []*BaseNode
+
+	for c := parent.FirstChild; c != nil; c = c.NextSibling {
+		got = append(got, c)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("%v has %d children, want %d", parent, len(got), len(want))
+	}
+
+	for i, c := range want {
+		var prev, next *BaseNode
+
+		if i > 0 {
+			prev = want[i-1]
+		}
+
+		if i+1 < len(want) {
+			next = want[i+1]
+		}
+
+		if got[i] != c || c.Parent != parent || c.PrevSibling != prev || c.NextSibling != next {
+			t.Fatalf("child %d of %v is %v with bad links", i, parent, got[i])
+		}
+	}
+
+	var first, last *BaseNode
+
+	if len(want) > 0 {
+		first, last = want[0], want[len(want)-1]
+	}
+
+	if parent.FirstChild != first || parent.LastChild != last {
+		t.Fatalf("%v has bad first or last child links", parent)
+	}
+}
+
+// TestReparent checks that appending or prepending a node that already has a
+// parent moves it out of its old parent.
+func TestReparent(t *testing.T) {
+	a, b := NewBaseNode("A", ""), NewBaseNode("B", "")
+	x, y, z := NewBaseNode("X", ""), NewBaseNode("Y", ""), NewBaseNode("Z", "")
+
+	_ = AppendChildren(a, []*BaseNode{x, y, z})
+
+	_ = b.AppendChild(y)
+
+	checkChildren(t, a, x, z)
+	checkChildren(t, b, y)
+
+	_ = b.PrependChild(z)
+
+	checkChildren(t, a, x)
+	checkChildren(t, b, z, y)
+
+	// Moving a child within its own parent.
+	_ = b.AppendChild(z)
+
+	checkChildren(t, b, y, z)
+
+	_ = b.AppendChild(x)
+
+	checkChildren(t, a)
+	checkChildren(t, b, y, z, x)
+}
+
+// TestAncestorRejected checks that the editing operations refuse to create a
+// cycle and leave the tree unchanged.
+func TestAncestorRejected(t *testing.T) {
+	root := mustSExpr(t, `(R (A (B (C))) (D))`)
+	a := root.FirstChild
+	b := a.FirstChild
+	c := b.FirstChild
+
+	ops := map[string]func() error{
+		"AppendChild":  func() error { return c.AppendChild(a) },
+		"PrependChild": func() error { return b.PrependChild(b) },
+		"InsertBefore": func() error { return c.InsertBefore(a) },
+		"InsertAfter":  func() error { return b.InsertAfter(root) },
+		"ReplaceWith":  func() error { return c.ReplaceWith(a) },
+	}
+
+	var bad *common.ErrBadParam
+
+	for name, op := range ops {
+		err := op()
+		if !errors.As(err, &bad) {
+			t.Errorf("%s() creating a cycle = %v, want a *common.ErrBadParam", name, err)
+		}
+	}
+
+	if got, want := sexprOf(t, root), `(R (A (B (C))) (D))`; got != want {
+		t.Fatalf("tree is %s after rejected edits, want %s", got, want)
+	}
+}
+
+// TestSiblingEdits checks the links after InsertBefore, InsertAfter and
+// ReplaceWith, at the edges and in the middle of the children.
+func TestSiblingEdits(t *testing.T) {
+	root := NewBaseNode("R", "")
+	b := NewBaseNode("B", "")
+
+	_ = root.AppendChild(b)
+
+	a, c := NewBaseNode("A", ""), NewBaseNode("C", "")
+
+	_ = b.InsertBefore(a)
+	_ = b.InsertAfter(c)
+
+	checkChildren(t, root, a, b, c)
+
+	ab := NewBaseNode("AB", "")
+
+	_ = a.InsertAfter(ab)
+
+	checkChildren(t, root, a, ab, b, c)
+
+	// Replacing the middle, the first and the last child.
+	m := NewBaseNode("M", "")
+
+	_ = ab.ReplaceWith(m)
+
+	checkChildren(t, root, a, m, b, c)
+
+	if ab.Parent != nil || ab.PrevSibling != nil || ab.NextSibling != nil {
+		t.Fatal("ReplaceWith() did not detach the replaced node")
+	}
+
+	f, l := NewBaseNode("F", ""), NewBaseNode("L", "")
+
+	_ = a.ReplaceWith(f)
+	_ = c.ReplaceWith(l)
+
+	checkChildren(t, root, f, m, b, l)
+
+	// Replacing with a sibling moves it.
+	_ = m.ReplaceWith(l)
+
+	checkChildren(t, root, f, l, b)
+
+	// Replacing with nil removes the node.
+	_ = b.ReplaceWith(nil)
+
+	checkChildren(t, root, f, l)
+
+	if root.InsertBefore(a) != ErrNoParent || root.ReplaceWith(a) != ErrNoParent {
+		t.Fatal("editing the siblings of a root did not return ErrNoParent")
+	}
+}
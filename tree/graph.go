@@ -0,0 +1,363 @@
+package tree
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	common "github.com/PlayerR9/mygo-data/common"
+)
+
+// LabelMode is the content of the labels of an exported graph.
+type LabelMode int
+
+const (
+	// LabelBoth labels the nodes with their type and, if not empty, their data.
+	LabelBoth LabelMode = iota
+
+	// LabelType labels the nodes with their type.
+	LabelType
+
+	// LabelData labels the nodes with their data.
+	LabelData
+)
+
+// GraphOptions are the options of the graph exporters. The zero value labels
+// nodes with their type and data, highlights nothing and collapses nothing.
+type GraphOptions struct {
+	// Label is the content of the labels.
+	Label LabelMode
+
+	// Highlight are the nodes to highlight.
+	Highlight []*BaseNode
+
+	// MaxDepth is the maximum depth to render, where the root is at depth 0.
+	// The children of nodes at the maximum depth are collapsed into a single
+	// placeholder node. If zero or negative, the depth is not limited.
+	MaxDepth int
+}
+
+// label returns the label of the node.
+//
+// Parameters:
+//   - node: The node to label.
+//
+// Returns:
+//   - string: The label of the node. Lines are separated by '\n'.
+func (o GraphOptions) label(node *BaseNode) string {
+	switch o.Label {
+	case LabelType:
+		return node.Type
+	case LabelData:
+		return node.Data
+	default:
+		if node.Data == "" {
+			return node.Type
+		}
+
+		return node.Type + "\n" + strconv.Quote(node.Data)
+	}
+}
+
+// graphItem is an item of an exported graph: either a node or a placeholder
+// for a collapsed subtree.
+type graphItem struct {
+	// id is the identifier of the item.
+	id string
+
+	// parent_id is the identifier of the parent item. Empty for the root.
+	parent_id string
+
+	// label is the label of the item.
+	label string
+
+	// highlighted is true if the item must be highlighted.
+	highlighted bool
+
+	// collapsed is true if the item is a placeholder for collapsed subtrees.
+	collapsed bool
+}
+
+// graphFrame is a node pending export.
+type graphFrame struct {
+	// node is the node to export.
+	node *BaseNode
+
+	// depth is the depth of the node.
+	depth int
+
+	// parent_id is the identifier of the parent item.
+	parent_id string
+}
+
+// graphItems walks the items of the graph in pre-order. The identifiers are
+// "n<i>" where <i> is the position of the item in the walk, so they are stable
+// for a given tree and options. The walk is not recursive.
+//
+// Parameters:
+//   - root: The root of the tree.
+//   - opts: The export options.
+//   - fn: The function called for each item. The walk stops if it returns
+//     false.
+func graphItems(root *BaseNode, opts GraphOptions, fn func(item graphItem) bool) {
+	highlight := make(map[*BaseNode]struct{}, len(opts.Highlight))
+
+	for _, node := range opts.Highlight {
+		highlight[node] = struct{}{}
+	}
+
+	var count int
+
+	stack := []graphFrame{{node: root}}
+
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		id := "n" + strconv.Itoa(count)
+		count++
+
+		_, highlighted := highlight[top.node]
+
+		ok := fn(graphItem{
+			id:          id,
+			parent_id:   top.parent_id,
+			label:       opts.label(top.node),
+			highlighted: highlighted,
+		})
+		if !ok {
+			return
+		}
+
+		if top.node.FirstChild == nil {
+			continue
+		}
+
+		if opts.MaxDepth > 0 && top.depth >= opts.MaxDepth {
+			var hidden int
+
+			for child := top.node.FirstChild; child != nil; child = child.NextSibling {
+				for range PreOrder(child) {
+					hidden++
+				}
+			}
+
+			ok := fn(graphItem{
+				id:        "n" + strconv.Itoa(count),
+				parent_id: id,
+				label:     "... " + strconv.Itoa(hidden) + " more",
+				collapsed: true,
+			})
+			if !ok {
+				return
+			}
+
+			count++
+
+			continue
+		}
+
+		for child := top.node.LastChild; child != nil; child = child.PrevSibling {
+			stack = append(stack, graphFrame{
+				node:      child,
+				depth:     top.depth + 1,
+				parent_id: id,
+			})
+		}
+	}
+}
+
+// WriteDOT writes the tree rooted at root as a Graphviz DOT digraph.
+//
+// Parameters:
+//   - w: The writer to write to.
+//   - root: The root of the tree to export.
+//   - opts: The export options.
+//
+// Returns:
+//   - error: An error if a parameter is nil or if a write failed.
+//
+// Errors:
+//   - common.ErrBadParam: If the writer or the root is nil.
+//   - any other error: Returned by the writer.
+func WriteDOT(w io.Writer, root *BaseNode, opts GraphOptions) error {
+	if w == nil {
+		err := common.NewErrNilParam("w")
+		return err
+	} else if root == nil {
+		err := common.NewErrNilParam("root")
+		return err
+	}
+
+	ew := &errWriter{w: w}
+
+	ew.WriteString("digraph tree {\n")
+	ew.WriteString("\tnode [shape=box];\n")
+
+	graphItems(root, opts, func(item graphItem) bool {
+		ew.WriteString("\t")
+		ew.WriteString(item.id)
+		ew.WriteString(" [label=\"")
+		ew.WriteString(escapeDOT(item.label))
+		ew.WriteString("\"")
+
+		if item.collapsed {
+			ew.WriteString(", style=dashed")
+		} else if item.highlighted {
+			ew.WriteString(", style=filled, fillcolor=yellow")
+		}
+
+		ew.WriteString("];\n")
+
+		if item.parent_id != "" {
+			ew.WriteString("\t")
+			ew.WriteString(item.parent_id)
+			ew.WriteString(" -> ")
+			ew.WriteString(item.id)
+
+			if item.collapsed {
+				ew.WriteString(" [style=dashed]")
+			}
+
+			ew.WriteString(";\n")
+		}
+
+		return ew.err == nil
+	})
+
+	ew.WriteString("}\n")
+
+	return ew.err
+}
+
+// escapeDOT escapes a label for a DOT double-quoted string.
+//
+// Parameters:
+//   - str: The label to escape.
+//
+// Returns:
+//   - string: The escaped label.
+func escapeDOT(str string) string {
+	var builder strings.Builder
+
+	for _, r := range str {
+		switch r {
+		case '\\', '"':
+			_, _ = builder.WriteRune('\\')
+			_, _ = builder.WriteRune(r)
+		case '\n':
+			_, _ = builder.WriteString(`\n`)
+		default:
+			_, _ = builder.WriteRune(r)
+		}
+	}
+
+	str = builder.String()
+	return str
+}
+
+// WriteMermaid writes the tree rooted at root as a Mermaid top-down flowchart.
+//
+// Parameters:
+//   - w: The writer to write to.
+//   - root: The root of the tree to export.
+//   - opts: The export options.
+//
+// Returns:
+//   - error: An error if a parameter is nil or if a write failed.
+//
+// Errors:
+//   - common.ErrBadParam: If the writer or the root is nil.
+//   - any other error: Returned by the writer.
+func WriteMermaid(w io.Writer, root *BaseNode, opts GraphOptions) error {
+	if w == nil {
+		err := common.NewErrNilParam("w")
+		return err
+	} else if root == nil {
+		err := common.NewErrNilParam("root")
+		return err
+	}
+
+	ew := &errWriter{w: w}
+
+	ew.WriteString("flowchart TD\n")
+
+	var highlighted, collapsed []string
+
+	graphItems(root, opts, func(item graphItem) bool {
+		ew.WriteString("\t")
+		ew.WriteString(item.id)
+		ew.WriteString("[\"")
+		ew.WriteString(escapeMermaid(item.label))
+		ew.WriteString("\"]\n")
+
+		if item.parent_id != "" {
+			ew.WriteString("\t")
+			ew.WriteString(item.parent_id)
+
+			if item.collapsed {
+				ew.WriteString(" -.-> ")
+			} else {
+				ew.WriteString(" --> ")
+			}
+
+			ew.WriteString(item.id)
+			ew.WriteString("\n")
+		}
+
+		if item.collapsed {
+			collapsed = append(collapsed, item.id)
+		} else if item.highlighted {
+			highlighted = append(highlighted, item.id)
+		}
+
+		return ew.err == nil
+	})
+
+	if len(highlighted) > 0 {
+		ew.WriteString("\tclassDef highlight fill:#ff0,stroke:#333\n")
+		ew.WriteString("\tclass ")
+		ew.WriteString(strings.Join(highlighted, ","))
+		ew.WriteString(" highlight\n")
+	}
+
+	if len(collapsed) > 0 {
+		ew.WriteString("\tclassDef collapsed stroke-dasharray:5 5\n")
+		ew.WriteString("\tclass ")
+		ew.WriteString(strings.Join(collapsed, ","))
+		ew.WriteString(" collapsed\n")
+	}
+
+	return ew.err
+}
+
+// escapeMermaid escapes a label for a Mermaid double-quoted string.
+//
+// Parameters:
+//   - str: The label to escape.
+//
+// Returns:
+//   - string: The escaped label.
+func escapeMermaid(str string) string {
+	var builder strings.Builder
+
+	for _, r := range str {
+		switch r {
+		case '#':
+			_, _ = builder.WriteString("#35;")
+		case '"':
+			_, _ = builder.WriteString("#quot;")
+		case '<':
+			_, _ = builder.WriteString("#lt;")
+		case '>':
+			_, _ = builder.WriteString("#gt;")
+		case '\n':
+			_, _ = builder.WriteString("<br/>")
+		default:
+			_, _ = builder.WriteRune(r)
+		}
+	}
+
+	str = builder.String()
+	return str
+}
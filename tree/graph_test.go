@@ -0,0 +1,237 @@
+package tree
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestGraphExport checks the DOT and Mermaid exports against golden outputs
+// for each label mode, with highlighting and with a maximum depth.
+func TestGraphExport(t *testing.T) {
+	root := mustSExpr(t, `(Root (A "x") (B (C (E)) (D "#<\"\n")))`)
+
+	tests := []struct {
+		name    string
+		opts    GraphOptions
+		dot     string
+		mermaid string
+	}{
+		{
+			name: "LabelBoth",
+			opts: GraphOptions{},
+			dot: `digraph tree {
+	node [shape=box];
+	n0 [label="Root"];
+	n1 [label="A\n\"x\""];
+	n0 -> n1;
+	n2 [label="B"];
+	n0 -> n2;
+	n3 [label="C"];
+	n2 -> n3;
+	n4 [label="E"];
+	n3 -> n4;
+	n5 [label="D\n\"#<\\\"\\n\""];
+	n2 -> n5;
+}
+`,
+			mermaid: `flowchart TD
+	n0["Root"]
+	n1["A<br/>#quot;x#quot;"]
+	n0 --> n1
+	n2["B"]
+	n0 --> n2
+	n3["C"]
+	n2 --> n3
+	n4["E"]
+	n3 --> n4
+	n5["D<br/>#quot;#35;#lt;\#quot;\n#quot;"]
+	n2 --> n5
+`,
+		},
+		{
+			name: "LabelType",
+			opts: GraphOptions{Label: LabelType},
+			dot: `digraph tree {
+	node [shape=box];
+	n0 [label="Root"];
+	n1 [label="A"];
+	n0 -> n1;
+	n2 [label="B"];
+	n0 -> n2;
+	n3 [label="C"];
+	n2 -> n3;
+	n4 [label="E"];
+	n3 -> n4;
+	n5 [label="D"];
+	n2 -> n5;
+}
+`,
+			mermaid: `flowchart TD
+	n0["Root"]
+	n1["A"]
+	n0 --> n1
+	n2["B"]
+	n0 --> n2
+	n3["C"]
+	n2 --> n3
+	n4["E"]
+	n3 --> n4
+	n5["D"]
+	n2 --> n5
+`,
+		},
+		{
+			name: "LabelData",
+			opts: GraphOptions{Label: LabelData},
+			dot: `digraph tree {
+	node [shape=box];
+	n0 [label=""];
+	n1 [label="x"];
+	n0 -> n1;
+	n2 [label=""];
+	n0 -> n2;
+	n3 [label=""];
+	n2 -> n3;
+	n4 [label=""];
+	n3 -> n4;
+	n5 [label="#<\"\n"];
+	n2 -> n5;
+}
+`,
+			mermaid: `flowchart TD
+	n0[""]
+	n1["x"]
+	n0 --> n1
+	n2[""]
+	n0 --> n2
+	n3[""]
+	n2 --> n3
+	n4[""]
+	n3 --> n4
+	n5["#35;#lt;#quot;<br/>"]
+	n2 --> n5
+`,
+		},
+		{
+			name: "Highlight",
+			opts: GraphOptions{
+				Label:     LabelType,
+				Highlight: []*BaseNode{root.FirstChild, root.LastChild.LastChild},
+			},
+			dot: `digraph tree {
+	node [shape=box];
+	n0 [label="Root"];
+	n1 [label="A", style=filled, fillcolor=yellow];
+	n0 -> n1;
+	n2 [label="B"];
+	n0 -> n2;
+	n3 [label="C"];
+	n2 -> n3;
+	n4 [label="E"];
+	n3 -> n4;
+	n5 [label="D", style=filled, fillcolor=yellow];
+	n2 -> n5;
+}
+`,
+			mermaid: `flowchart TD
+	n0["Root"]
+	n1["A"]
+	n0 --> n1
+	n2["B"]
+	n0 --> n2
+	n3["C"]
+	n2 --> n3
+	n4["E"]
+	n3 --> n4
+	n5["D"]
+	n2 --> n5
+	classDef highlight fill:#ff0,stroke:#333
+	class n1,n5 highlight
+`,
+		},
+		{
+			name: "MaxDepth",
+			opts: GraphOptions{
+				Label:     LabelType,
+				Highlight: []*BaseNode{root.LastChild.FirstChild},
+				MaxDepth:  1,
+			},
+			dot: `digraph tree {
+	node [shape=box];
+	n0 [label="Root"];
+	n1 [label="A"];
+	n0 -> n1;
+	n2 [label="B"];
+	n0 -> n2;
+	n3 [label="... 3 more", style=dashed];
+	n2 -> n3 [style=dashed];
+}
+`,
+			mermaid: `flowchart TD
+	n0["Root"]
+	n1["A"]
+	n0 --> n1
+	n2["B"]
+	n0 --> n2
+	n3["... 3 more"]
+	n2 -.-> n3
+	classDef collapsed stroke-dasharray:5 5
+	class n3 collapsed
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		var dot, mermaid strings.Builder
+
+		err := WriteDOT(&dot, root, tt.opts)
+		if err != nil {
+			t.Fatalf("%s: WriteDOT() = %v", tt.name, err)
+		}
+
+		err = WriteMermaid(&mermaid, root, tt.opts)
+		if err != nil {
+			t.Fatalf("%s: WriteMermaid() = %v", tt.name, err)
+		}
+
+		if got := dot.String(); got != tt.dot {
+			t.Errorf("%s: WriteDOT() =\n%s\nwant\n%s", tt.name, got, tt.dot)
+		}
+
+		if got := mermaid.String(); got != tt.mermaid {
+			t.Errorf("%s: WriteMermaid() =\n%s\nwant\n%s", tt.name, got, tt.mermaid)
+		}
+	}
+}
+
+// TestGraphExportErrors checks that the exporters report a nil writer, a nil
+// root and a failing writer.
+func TestGraphExportErrors(t *testing.T) {
+	root := mustSExpr(t, `(A (B))`)
+
+	var builder strings.Builder
+
+	if WriteDOT(nil, root, GraphOptions{}) == nil {
+		t.Error("WriteDOT() on a nil writer succeeded")
+	}
+
+	if WriteDOT(&builder, nil, GraphOptions{}) == nil {
+		t.Error("WriteDOT() of a nil root succeeded")
+	}
+
+	if err := WriteDOT(failingWriter{}, root, GraphOptions{}); err != errFailingWriter {
+		t.Errorf("WriteDOT() = %v, want %v", err, errFailingWriter)
+	}
+
+	if WriteMermaid(nil, root, GraphOptions{}) == nil {
+		t.Error("WriteMermaid() on a nil writer succeeded")
+	}
+
+	if WriteMermaid(&builder, nil, GraphOptions{}) == nil {
+		t.Error("WriteMermaid() of a nil root succeeded")
+	}
+
+	if err := WriteMermaid(failingWriter{}, root, GraphOptions{}); err != errFailingWriter {
+		t.Errorf("WriteMermaid() = %v, want %v", err, errFailingWriter)
+	}
+}
@@ -12,16 +12,17 @@ import (
 //   - nodes: The slice of *Node to prepend to the parent's children.
 //
 // Returns:
-//   - error: An error if the parent is nil, or if any of the nodes in the slice
-//     are nil.
+//   - error: An error if the parent is nil, or if any of the nodes could not be
+//     linked to the parent.
 //
 // Errors:
-//   - common.ErrBadParam: If the parent is nil.
+//   - common.ErrBadParam: If the parent is nil or if any of the nodes is the
+//     parent or one of its ancestors.
 //
 // Behaviors:
 //   - There is a side-effect on the nodes: nil nodes are removed and the slice is
 //     trimmed.
-func PrependChildren(parent *BaseNode, nodes []*BaseNode) error {
+func PrependChildren[T comparable, D any](parent *GenericNode[T, D], nodes []*GenericNode[T, D]) error {
 	if parent == nil {
 		err := common.NewErrBadParam("parent", "is nil")
 		return err
@@ -39,7 +40,10 @@ func PrependChildren(parent *BaseNode, nodes []*BaseNode) error {
 	for i := len(nodes) - 1; i >= 0; i-- {
 		node := nodes[i]
 
-		_ = parent.PrependChild(node)
+		err := parent.PrependChild(node)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -52,16 +56,17 @@ func PrependChildren(parent *BaseNode, nodes []*BaseNode) error {
 //   - nodes: The slice of *Node to append to the parent's children.
 //
 // Returns:
-//   - error: An error if the parent is nil, or if any of the nodes in the slice
-//     are nil.
+//   - error: An error if the parent is nil, or if any of the nodes could not be
+//     linked to the parent.
 //
 // Errors:
-//   - common.ErrBadParam: If the parent is nil.
+//   - common.ErrBadParam: If the parent is nil or if any of the nodes is the
+//     parent or one of its ancestors.
 //
 // Behaviors:
 //   - There is a side-effect on the nodes: nil nodes are removed and the slice is
 //     trimmed.
-func AppendChildren(parent *BaseNode, nodes []*BaseNode) error {
+func AppendChildren[T comparable, D any](parent *GenericNode[T, D], nodes []*GenericNode[T, D]) error {
 	if parent == nil {
 		err := common.NewErrBadParam("parent", "is nil")
 		return err
@@ -77,8 +82,117 @@ func AppendChildren(parent *BaseNode, nodes []*BaseNode) error {
 	}
 
 	for _, node := range nodes {
-		_ = parent.AppendChild(node)
+		err := parent.AppendChild(node)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
+
+// insertChildAt inserts the child at the given position among the parent's
+// children.
+//
+// Parameters:
+//   - parent: The parent to insert the child into. Assumed to be non-nil.
+//   - child: The child to insert.
+//   - idx: The position of the child once inserted, starting from 0.
+//
+// Returns:
+//   - error: An error if the child could not be inserted.
+//
+// Errors:
+//   - common.ErrBadParam: If the index is out of range or if the child is the
+//     parent or one of its ancestors.
+func insertChildAt[T comparable, D any](parent, child *GenericNode[T, D], idx int) error {
+	if idx < 0 {
+		err := common.NewErrBadParam("idx", "must not be negative")
+		return err
+	}
+
+	if idx == 0 {
+		err := parent.PrependChild(child)
+		return err
+	}
+
+	prev := parent.FirstChild
+
+	for i := 1; i < idx && prev != nil; i++ {
+		prev = prev.NextSibling
+	}
+
+	if prev == nil {
+		err := common.NewErrBadParam("idx", "is out of range")
+		return err
+	}
+
+	err := prev.InsertAfter(child)
+	return err
+}
+
+// siblingIndex returns the index of the node among the children of its parent.
+//
+// Parameters:
+//   - node: The node. Assumed to be non-nil.
+//
+// Returns:
+//   - int: The index of the node, starting from 0.
+func siblingIndex[T comparable, D any](node *GenericNode[T, D]) int {
+	var idx int
+
+	for s := node.PrevSibling; s != nil; s = s.PrevSibling {
+		idx++
+	}
+
+	return idx
+}
+
+// pathOf returns the path of child indexes from the root to the node.
+//
+// Parameters:
+//   - root: The root the path starts from.
+//   - node: The node to compute the path of. Assumed to be a descendant of
+//     the root or the root itself.
+//
+// Returns:
+//   - []int: The path of child indexes. Nil if the node is the root.
+func pathOf[T comparable, D any](root, node *GenericNode[T, D]) []int {
+	var path []int
+
+	for n := node; n != root && n != nil; n = n.Parent {
+		path = append(path, siblingIndex(n))
+	}
+
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+
+	return path
+}
+
+// nodeAt returns the node at the given path of child indexes from the root.
+//
+// Parameters:
+//   - root: The root the path starts from.
+//   - path: The path of child indexes.
+//
+// Returns:
+//   - *GenericNode[T, D]: The node at the path. Nil if there is none.
+func nodeAt[T comparable, D any](root *GenericNode[T, D], path []int) *GenericNode[T, D] {
+	n := root
+
+	for _, idx := range path {
+		if n == nil || idx < 0 {
+			return nil
+		}
+
+		n = n.FirstChild
+
+		for i := 0; i < idx && n != nil; i++ {
+			n = n.NextSibling
+		}
+	}
+
+	return n
+}
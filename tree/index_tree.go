@@ -0,0 +1,456 @@
+package tree
+
+import (
+	"iter"
+	"math"
+
+	common "github.com/PlayerR9/mygo-data/common"
+)
+
+// NodeID identifies a node of an IndexTree.
+type NodeID int32
+
+const (
+	// NoNode is the NodeID of a missing node.
+	NoNode NodeID = -1
+
+	// maxIndexNodes is the maximum number of nodes of an IndexTree: one more
+	// than the largest NodeID.
+	maxIndexNodes int64 = math.MaxInt32 + 1
+)
+
+// indexNode is a node of an IndexTree.
+type indexNode[T comparable, D any] struct {
+	// type_ is the type of the node.
+	type_ T
+
+	// data is the data associated with the node.
+	data D
+
+	// parent, next, prev, first and last are the links to other nodes.
+	parent, next, prev, first, last NodeID
+}
+
+// IndexTree stores the nodes of one or more trees in a single slice and links
+// them with 32-bit indexes instead of pointers. This halves the size of the
+// links on 64-bit platforms and gives the garbage collector no pointers to
+// scan besides the types and data, which makes it suited to very large trees
+// that are built once and then read.
+//
+// Nodes are never freed individually; the whole storage is freed at once by
+// Reset. An IndexTree holds at most 2^31 nodes, the number of ids a NodeID can
+// hold. It is not safe for concurrent use.
+type IndexTree[T comparable, D any] struct {
+	// nodes are the nodes, indexed by their NodeID.
+	nodes []indexNode[T, D]
+
+	// max_nodes is the maximum number of nodes of the tree. If zero,
+	// maxIndexNodes is used; only tests set it.
+	max_nodes int64
+}
+
+// NewIndexTree creates a new, empty IndexTree.
+//
+// Parameters:
+//   - capacity: The number of nodes to reserve space for. If zero or negative,
+//     no space is reserved.
+//
+// Returns:
+//   - *IndexTree[T, D]: The new tree. Never returns nil.
+func NewIndexTree[T comparable, D any](capacity int) *IndexTree[T, D] {
+	t := &IndexTree[T, D]{}
+
+	if capacity > 0 {
+		t.nodes = make([]indexNode[T, D], 0, capacity)
+	}
+
+	return t
+}
+
+// valid checks whether the id is the id of a node of the tree.
+//
+// Parameters:
+//   - id: The id to check.
+//
+// Returns:
+//   - bool: True if the id is valid, false otherwise.
+func (t *IndexTree[T, D]) valid(id NodeID) bool {
+	return t != nil && id >= 0 && int(id) < len(t.nodes)
+}
+
+// New adds a new node, without parent nor children, to the tree.
+//
+// Parameters:
+//   - type_: The type of the node.
+//   - data: The data associated with the node.
+//
+// Returns:
+//   - NodeID: The id of the new node. NoNode if an error occurred.
+//   - error: An error if the node could not be added.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - ErrTreeFull: If the tree already holds 2^31 nodes, the number of ids a
+//     NodeID can hold.
+func (t *IndexTree[T, D]) New(type_ T, data D) (NodeID, error) {
+	if t == nil {
+		return NoNode, common.ErrNilReceiver
+	}
+
+	max_nodes := t.max_nodes
+	if max_nodes == 0 {
+		max_nodes = maxIndexNodes
+	}
+
+	if int64(len(t.nodes)) >= max_nodes {
+		return NoNode, ErrTreeFull
+	}
+
+	id := NodeID(len(t.nodes))
+
+	t.nodes = append(t.nodes, indexNode[T, D]{
+		type_:  type_,
+		data:   data,
+		parent: NoNode,
+		next:   NoNode,
+		prev:   NoNode,
+		first:  NoNode,
+		last:   NoNode,
+	})
+
+	return id, nil
+}
+
+// Len returns the number of nodes of the tree.
+//
+// Returns:
+//   - int: The number of nodes.
+func (t *IndexTree[T, D]) Len() int {
+	if t == nil {
+		return 0
+	}
+
+	return len(t.nodes)
+}
+
+// Reset frees all the nodes at once. The ids previously returned by New must
+// not be used afterwards. The storage is kept for the next nodes.
+func (t *IndexTree[T, D]) Reset() {
+	if t == nil {
+		return
+	}
+
+	clear(t.nodes)
+	t.nodes = t.nodes[:0]
+}
+
+// Type returns the type of the node.
+//
+// Parameters:
+//   - id: The id of the node.
+//
+// Returns:
+//   - T: The type of the node. The zero value if the id is not valid.
+func (t *IndexTree[T, D]) Type(id NodeID) T {
+	if !t.valid(id) {
+		return *new(T)
+	}
+
+	return t.nodes[id].type_
+}
+
+// Data returns the data of the node.
+//
+// Parameters:
+//   - id: The id of the node.
+//
+// Returns:
+//   - D: The data of the node. The zero value if the id is not valid.
+func (t *IndexTree[T, D]) Data(id NodeID) D {
+	if !t.valid(id) {
+		return *new(D)
+	}
+
+	return t.nodes[id].data
+}
+
+// SetData sets the data of the node.
+//
+// Parameters:
+//   - id: The id of the node.
+//   - data: The new data.
+//
+// Returns:
+//   - error: An error if the id is not valid.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If the id is not valid.
+func (t *IndexTree[T, D]) SetData(id NodeID, data D) error {
+	if t == nil {
+		return common.ErrNilReceiver
+	} else if !t.valid(id) {
+		err := common.NewErrBadParam("id", "is not a node of the tree")
+		return err
+	}
+
+	t.nodes[id].data = data
+
+	return nil
+}
+
+// Parent returns the parent of the node.
+//
+// Parameters:
+//   - id: The id of the node.
+//
+// Returns:
+//   - NodeID: The id of the parent. NoNode if there is none or if the id
+//     is not valid.
+func (t *IndexTree[T, D]) Parent(id NodeID) NodeID {
+	if !t.valid(id) {
+		return NoNode
+	}
+
+	return t.nodes[id].parent
+}
+
+// FirstChild returns the first child of the node.
+//
+// Parameters:
+//   - id: The id of the node.
+//
+// Returns:
+//   - NodeID: The id of the first child. NoNode if there is none or if
+//     the id is not valid.
+func (t *IndexTree[T, D]) FirstChild(id NodeID) NodeID {
+	if !t.valid(id) {
+		return NoNode
+	}
+
+	return t.nodes[id].first
+}
+
+// LastChild returns the last child of the node.
+//
+// Parameters:
+//   - id: The id of the node.
+//
+// Returns:
+//   - NodeID: The id of the last child. NoNode if there is none or if
+//     the id is not valid.
+func (t *IndexTree[T, D]) LastChild(id NodeID) NodeID {
+	if !t.valid(id) {
+		return NoNode
+	}
+
+	return t.nodes[id].last
+}
+
+// NextSibling returns the next sibling of the node.
+//
+// Parameters:
+//   - id: The id of the node.
+//
+// Returns:
+//   - NodeID: The id of the next sibling. NoNode if there is none or if
+//     the id is not valid.
+func (t *IndexTree[T, D]) NextSibling(id NodeID) NodeID {
+	if !t.valid(id) {
+		return NoNode
+	}
+
+	return t.nodes[id].next
+}
+
+// PrevSibling returns the previous sibling of the node.
+//
+// Parameters:
+//   - id: The id of the node.
+//
+// Returns:
+//   - NodeID: The id of the previous sibling. NoNode if there is none or
+//     if the id is not valid.
+func (t *IndexTree[T, D]) PrevSibling(id NodeID) NodeID {
+	if !t.valid(id) {
+		return NoNode
+	}
+
+	return t.nodes[id].prev
+}
+
+// Children returns an iterator over the children of the node, in order.
+//
+// Parameters:
+//   - id: The id of the node.
+//
+// Returns:
+//   - iter.Seq[NodeID]: An iterator over the ids of the children. Never
+//     returns nil.
+func (t *IndexTree[T, D]) Children(id NodeID) iter.Seq[NodeID] {
+	fn := func(yield func(NodeID) bool) {
+		for c := t.FirstChild(id); c != NoNode; c = t.nodes[c].next {
+			if !yield(c) {
+				return
+			}
+		}
+	}
+
+	return fn
+}
+
+// Detach removes the node from its parent and siblings, making it the root of
+// its own subtree. The node keeps its children.
+//
+// Parameters:
+//   - id: The id of the node.
+//
+// Returns:
+//   - error: An error if the id is not valid.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If the id is not valid.
+func (t *IndexTree[T, D]) Detach(id NodeID) error {
+	if t == nil {
+		return common.ErrNilReceiver
+	} else if !t.valid(id) {
+		err := common.NewErrBadParam("id", "is not a node of the tree")
+		return err
+	}
+
+	n := &t.nodes[id]
+
+	if n.parent == NoNode {
+		return nil
+	}
+
+	if n.prev == NoNode {
+		t.nodes[n.parent].first = n.next
+	} else {
+		t.nodes[n.prev].next = n.next
+	}
+
+	if n.next == NoNode {
+		t.nodes[n.parent].last = n.prev
+	} else {
+		t.nodes[n.next].prev = n.prev
+	}
+
+	n.parent = NoNode
+	n.prev = NoNode
+	n.next = NoNode
+
+	return nil
+}
+
+// AppendChild appends the child to the children of the parent. If the child
+// already has a parent, it is detached first.
+//
+// Parameters:
+//   - parent: The id of the parent.
+//   - child: The id of the child.
+//
+// Returns:
+//   - error: An error if the child could not be appended.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If an id is not valid or if the child is the parent
+//     or one of its ancestors.
+func (t *IndexTree[T, D]) AppendChild(parent, child NodeID) error {
+	if t == nil {
+		return common.ErrNilReceiver
+	} else if !t.valid(parent) {
+		err := common.NewErrBadParam("parent", "is not a node of the tree")
+		return err
+	} else if !t.valid(child) {
+		err := common.NewErrBadParam("child", "is not a node of the tree")
+		return err
+	}
+
+	// A leaf cannot be an ancestor of another node.
+	if child == parent || t.nodes[child].first != NoNode {
+		for n := parent; n != NoNode; n = t.nodes[n].parent {
+			if n == child {
+				err := common.NewErrBadParam("child", "must not be the parent or one of its ancestors")
+				return err
+			}
+		}
+	}
+
+	_ = t.Detach(child)
+
+	p := &t.nodes[parent]
+	c := &t.nodes[child]
+
+	c.parent = parent
+	c.prev = p.last
+
+	if p.last == NoNode {
+		p.first = child
+	} else {
+		t.nodes[p.last].next = child
+	}
+
+	p.last = child
+
+	return nil
+}
+
+// ToTree converts the subtree rooted at the given node into a pointer-based
+// tree. The conversion is not recursive.
+//
+// Parameters:
+//   - root: The id of the root of the subtree.
+//   - arena: The arena to allocate the nodes from. If nil, the nodes are
+//     allocated individually.
+//
+// Returns:
+//   - *GenericNode[T, D]: The root of the converted tree. Nil if the id is not
+//     valid.
+func (t *IndexTree[T, D]) ToTree(root NodeID, arena *Arena[T, D]) *GenericNode[T, D] {
+	if !t.valid(root) {
+		return nil
+	}
+
+	alloc := func(id NodeID) *GenericNode[T, D] {
+		n := &t.nodes[id]
+
+		if arena == nil {
+			return NewGenericNode(n.type_, n.data)
+		}
+
+		return arena.New(n.type_, n.data)
+	}
+
+	type frame struct {
+		// id is the node to convert.
+		id NodeID
+
+		// parent is the converted parent of the node.
+		parent *GenericNode[T, D]
+	}
+
+	tree_root := alloc(root)
+
+	var stack []frame
+
+	for c := t.nodes[root].last; c != NoNode; c = t.nodes[c].prev {
+		stack = append(stack, frame{id: c, parent: tree_root})
+	}
+
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		n := alloc(top.id)
+		_ = top.parent.AppendChild(n)
+
+		for c := t.nodes[top.id].last; c != NoNode; c = t.nodes[c].prev {
+			stack = append(stack, frame{id: c, parent: n})
+		}
+	}
+
+	return tree_root
+}
@@ -0,0 +1,226 @@
+package tree
+
+import (
+	"testing"
+)
+
+// benchTreeSize is the number of nodes of the trees built by the benchmarks.
+const benchTreeSize int = 1_000_000
+
+// benchFanout is the number of children of the inner nodes of the trees built
+// by the benchmarks.
+const benchFanout int = 8
+
+// buildBaseTree builds a tree of n nodes with NewBaseNode, in which the node i
+// is a child of the node (i-1)/benchFanout.
+//
+// Parameters:
+//   - n: The number of nodes.
+//
+// Returns:
+//   - *BaseNode: The root of the tree.
+func buildBaseTree(n int) *BaseNode {
+	nodes := make([]*BaseNode, n)
+
+	for i := range n {
+		nodes[i] = NewBaseNode("Node", "")
+
+		if i > 0 {
+			_ = nodes[(i-1)/benchFanout].AppendChild(nodes[i])
+		}
+	}
+
+	return nodes[0]
+}
+
+// buildArenaTree builds the same tree as buildBaseTree with an Arena.
+//
+// Parameters:
+//   - arena: The arena to allocate the nodes from.
+//   - n: The number of nodes.
+//
+// Returns:
+//   - *BaseNode: The root of the tree.
+func buildArenaTree(arena *Arena[string, string], n int) *BaseNode {
+	nodes := make([]*BaseNode, n)
+
+	for i := range n {
+		nodes[i] = arena.New("Node", "")
+
+		if i > 0 {
+			_ = nodes[(i-1)/benchFanout].AppendChild(nodes[i])
+		}
+	}
+
+	return nodes[0]
+}
+
+// buildIndexTree builds the same tree as buildBaseTree in an IndexTree.
+//
+// Parameters:
+//   - t: The tree to add the nodes to.
+//   - n: The number of nodes.
+//
+// Returns:
+//   - NodeID: The root of the tree.
+func buildIndexTree(t *IndexTree[string, string], n int) NodeID {
+	for i := range n {
+		id, _ := t.New("Node", "")
+
+		if i > 0 {
+			_ = t.AppendChild(NodeID((i-1)/benchFanout), id)
+		}
+	}
+
+	return 0
+}
+
+// countIndexTree counts the nodes of the tree rooted at root by following its
+// links, without recursion.
+//
+// Parameters:
+//   - t: The tree.
+//   - root: The root of the tree.
+//
+// Returns:
+//   - int: The number of nodes.
+func countIndexTree(t *IndexTree[string, string], root NodeID) int {
+	count := 1
+
+	n := root
+
+	for {
+		if c := t.FirstChild(n); c != NoNode {
+			n = c
+			count++
+
+			continue
+		}
+
+		for n != root && t.NextSibling(n) == NoNode {
+			n = t.Parent(n)
+		}
+
+		if n == root {
+			return count
+		}
+
+		n = t.NextSibling(n)
+		count++
+	}
+}
+
+// BenchmarkBuildTree compares building a tree of a million nodes with
+// NewBaseNode, an Arena and an IndexTree.
+func BenchmarkBuildTree(b *testing.B) {
+	b.Run("NewBaseNode", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for range b.N {
+			_ = buildBaseTree(benchTreeSize)
+		}
+	})
+
+	b.Run("Arena", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for range b.N {
+			arena := NewArena[string, string](DefaultArenaBlockSize)
+			_ = buildArenaTree(arena, benchTreeSize)
+		}
+	})
+
+	b.Run("IndexTree", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for range b.N {
+			t := NewIndexTree[string, string](benchTreeSize)
+			_ = buildIndexTree(t, benchTreeSize)
+		}
+	})
+}
+
+// BenchmarkTraverseTree compares walking a tree of a million nodes built with
+// NewBaseNode, an Arena and an IndexTree.
+func BenchmarkTraverseTree(b *testing.B) {
+	b.Run("NewBaseNode", func(b *testing.B) {
+		root := buildBaseTree(benchTreeSize)
+
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		for range b.N {
+			if Size(root) != benchTreeSize {
+				b.Fatal("wrong tree size")
+			}
+		}
+	})
+
+	b.Run("Arena", func(b *testing.B) {
+		root := buildArenaTree(NewArena[string, string](DefaultArenaBlockSize), benchTreeSize)
+
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		for range b.N {
+			if Size(root) != benchTreeSize {
+				b.Fatal("wrong tree size")
+			}
+		}
+	})
+
+	b.Run("IndexTree", func(b *testing.B) {
+		t := NewIndexTree[string, string](benchTreeSize)
+		root := buildIndexTree(t, benchTreeSize)
+
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		for range b.N {
+			if countIndexTree(t, root) != benchTreeSize {
+				b.Fatal("wrong tree size")
+			}
+		}
+	})
+}
+
+// TestIndexTreeFull checks that New fails instead of wrapping the ids around
+// once the tree holds as many nodes as a NodeID can identify.
+func TestIndexTreeFull(t *testing.T) {
+	it := NewIndexTree[string, string](0)
+	it.max_nodes = 3
+
+	for i := range 3 {
+		id, err := it.New("Node", "")
+		if err != nil || id != NodeID(i) {
+			t.Fatalf("New() = %d, %v, want %d, nil", id, err, i)
+		}
+	}
+
+	id, err := it.New("Node", "")
+	if err != ErrTreeFull || id != NoNode {
+		t.Fatalf("New() = %d, %v, want NoNode, ErrTreeFull", id, err)
+	}
+}
+
+// TestIndexTreeToTree checks that an IndexTree converts to the same tree as
+// the one built with NewBaseNode.
+func TestIndexTreeToTree(t *testing.T) {
+	it := NewIndexTree[string, string](0)
+	root := buildIndexTree(it, 100)
+
+	if got := countIndexTree(it, root); got != 100 {
+		t.Fatalf("countIndexTree() = %d, want 100", got)
+	}
+
+	converted := it.ToTree(root, NewArena[string, string](16))
+
+	if !Equal(converted, buildBaseTree(100)) {
+		t.Fatal("ToTree() differs from the tree built with NewBaseNode")
+	}
+
+	err := Validate(converted)
+	if err != nil {
+		t.Fatalf("ToTree() produced an invalid tree: %v", err)
+	}
+}
@@ -0,0 +1,324 @@
+package tree
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+
+	common "github.com/PlayerR9/mygo-data/common"
+)
+
+// jsonFrame is a pending step of the JSON encoder.
+type jsonFrame struct {
+	// node is the node to encode.
+	node *BaseNode
+
+	// close is true if the frame closes the node instead of opening it.
+	close bool
+
+	// comma is true if a comma must precede the node.
+	comma bool
+}
+
+// MarshalJSON encodes the tree rooted at root as nested JSON objects of the
+// form:
+//
+//	{"type": <type>, "data": <data>, "children": [<child>, ...]}
+//
+// The "data" and "children" fields are omitted when empty. Nodes with a source
+// span also have a "span" field holding the JSON encoding of their Span,
+// unless WithSpans(false) is given. The encoding is not recursive.
+//
+// Parameters:
+//   - root: The root of the tree to encode.
+//   - opts: The options of the encoding.
+//
+// Returns:
+//   - []byte: The JSON encoding of the tree.
+//   - error: An error if the root is nil.
+//
+// Errors:
+//   - common.ErrBadParam: If the root is nil.
+func MarshalJSON(root *BaseNode, opts ...EncodeOption) ([]byte, error) {
+	if root == nil {
+		err := common.NewErrNilParam("root")
+		return nil, err
+	}
+
+	o := newEncodeOptions(true, opts)
+
+	var buf bytes.Buffer
+
+	stack := []jsonFrame{{node: root}}
+
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if top.close {
+			_, _ = buf.WriteString("]}")
+			continue
+		}
+
+		if top.comma {
+			_ = buf.WriteByte(',')
+		}
+
+		_, _ = buf.WriteString(`{"type":`)
+		writeJSONString(&buf, top.node.Type)
+
+		if top.node.Data != "" {
+			_, _ = buf.WriteString(`,"data":`)
+			writeJSONString(&buf, top.node.Data)
+		}
+
+		if o.spans && top.node.Span != nil {
+			data, _ := json.Marshal(top.node.Span) // Spans always marshal.
+
+			_, _ = buf.WriteString(`,"span":`)
+			_, _ = buf.Write(data)
+		}
+
+		if top.node.FirstChild == nil {
+			_ = buf.WriteByte('}')
+			continue
+		}
+
+		_, _ = buf.WriteString(`,"children":[`)
+
+		stack = append(stack, jsonFrame{node: top.node, close: true})
+
+		for child := top.node.LastChild; child != nil; child = child.PrevSibling {
+			stack = append(stack, jsonFrame{
+				node:  child,
+				comma: child.PrevSibling != nil,
+			})
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeJSONString writes the JSON encoding of the given string to the buffer.
+//
+// Parameters:
+//   - buf: The buffer to write to.
+//   - str: The string to encode.
+func writeJSONString(buf *bytes.Buffer, str string) {
+	data, _ := json.Marshal(str) // Strings always marshal.
+	_, _ = buf.Write(data)
+}
+
+// jsonDecodeFrame is a node being decoded.
+type jsonDecodeFrame struct {
+	// node is the node being decoded.
+	node *BaseNode
+
+	// in_children is true if the decoder is inside the node's children array.
+	in_children bool
+
+	// has_type is true if the node's type was decoded.
+	has_type bool
+}
+
+// UnmarshalJSON decodes a tree encoded by MarshalJSON. The decoding is not
+// recursive, but encoding/json limits the nesting of the input to 10000
+// levels, so trees deeper than 5000 nodes cannot be decoded.
+//
+// Parameters:
+//   - data: The JSON encoding of the tree.
+//
+// Returns:
+//   - *BaseNode: The root of the decoded tree. Nil if an error occurred.
+//   - error: An error if the data is not a valid encoding of a tree.
+//
+// Errors:
+//   - *ErrSyntax: If the data is not a valid encoding of a tree. Its offset is
+//     that of the offending token.
+func UnmarshalJSON(data []byte) (*BaseNode, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	start := jsonTokenStart(data, 0)
+
+	tok, err := dec.Token()
+	if err != nil {
+		err := jsonError(dec, err)
+		return nil, err
+	}
+
+	if tok != json.Delim('{') {
+		err := NewErrSyntax("JSON", start, "expected a node object")
+		return nil, err
+	}
+
+	root := &BaseNode{}
+
+	stack := []*jsonDecodeFrame{{node: root}}
+
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+
+		start := jsonTokenStart(data, dec.InputOffset())
+
+		tok, err := dec.Token()
+		if err != nil {
+			err := jsonError(dec, err)
+			return nil, err
+		}
+
+		if top.in_children {
+			switch tok {
+			case json.Delim('{'):
+				child := &BaseNode{}
+				_ = top.node.AppendChild(child)
+
+				stack = append(stack, &jsonDecodeFrame{node: child})
+			case json.Delim(']'):
+				top.in_children = false
+			default:
+				err := NewErrSyntax("JSON", start, "expected a node object in \"children\"")
+				return nil, err
+			}
+
+			continue
+		}
+
+		if tok == json.Delim('}') {
+			if !top.has_type {
+				err := NewErrSyntax("JSON", start, "missing \"type\" field")
+				return nil, err
+			}
+
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		key, _ := tok.(string) // Object keys are always strings.
+
+		switch key {
+		case "type", "data", "children", "span":
+		default:
+			err := NewErrSyntax("JSON", start, "unknown field "+strconv.Quote(key))
+			return nil, err
+		}
+
+		start = jsonTokenStart(data, dec.InputOffset())
+
+		if key == "span" {
+			var span Span
+
+			err := dec.Decode(&span)
+			if err != nil {
+				var type_err *json.UnmarshalTypeError
+
+				if errors.As(err, &type_err) {
+					err := NewErrSyntax("JSON", start, "field \"span\" must be a span object")
+					return nil, err
+				}
+
+				err := jsonError(dec, err)
+				return nil, err
+			}
+
+			top.node.Span = &span
+
+			continue
+		}
+
+		tok, err = dec.Token()
+		if err != nil {
+			err := jsonError(dec, err)
+			return nil, err
+		}
+
+		switch key {
+		case "type":
+			str, ok := tok.(string)
+			if !ok {
+				err := NewErrSyntax("JSON", start, "field \"type\" must be a string")
+				return nil, err
+			}
+
+			top.node.Type = str
+			top.has_type = true
+		case "data":
+			str, ok := tok.(string)
+			if !ok {
+				err := NewErrSyntax("JSON", start, "field \"data\" must be a string")
+				return nil, err
+			}
+
+			top.node.Data = str
+		case "children":
+			if tok != json.Delim('[') {
+				err := NewErrSyntax("JSON", start, "field \"children\" must be an array")
+				return nil, err
+			}
+
+			top.in_children = true
+		}
+	}
+
+	start = jsonTokenStart(data, dec.InputOffset())
+
+	_, err = dec.Token()
+	if err != io.EOF {
+		err := NewErrSyntax("JSON", start, "unexpected data after the root node")
+		return nil, err
+	}
+
+	return root, nil
+}
+
+// jsonTokenStart returns the offset of the next token of the input, skipping
+// the whitespace and the separators the decoder has not consumed yet.
+//
+// Parameters:
+//   - data: The input.
+//   - offset: The offset at which the decoder stopped.
+//
+// Returns:
+//   - int: The offset of the next token. The length of the input if there is
+//     none.
+func jsonTokenStart(data []byte, offset int64) int {
+	i := int(offset)
+
+	for i < len(data) {
+		switch data[i] {
+		case ' ', '\t', '\n', '\r', ':', ',':
+			i++
+		default:
+			return i
+		}
+	}
+
+	return i
+}
+
+// jsonError converts an error returned by the JSON decoder into an ErrSyntax.
+//
+// Parameters:
+//   - dec: The decoder that returned the error.
+//   - err: The error returned by the decoder.
+//
+// Returns:
+//   - error: An instance of ErrSyntax. Never returns nil.
+func jsonError(dec *json.Decoder, err error) error {
+	var syntax_err *json.SyntaxError
+
+	if errors.As(err, &syntax_err) {
+		// The offset of a syntax error is the one after the offending byte.
+		err := NewErrSyntax("JSON", max(int(syntax_err.Offset)-1, 0), syntax_err.Error())
+		return err
+	}
+
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err := NewErrSyntax("JSON", int(dec.InputOffset()), "unexpected end of input")
+		return err
+	}
+
+	err = NewErrSyntax("JSON", int(dec.InputOffset()), err.Error())
+	return err
+}
@@ -0,0 +1,111 @@
+package tree
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestJSONRoundTrip checks that UnmarshalJSON decodes exactly the trees
+// encoded by MarshalJSON.
+func TestJSONRoundTrip(t *testing.T) {
+	for _, root := range roundTripTrees(5000) {
+		data, err := MarshalJSON(root)
+		if err != nil {
+			t.Fatalf("MarshalJSON returned %v", err)
+		}
+
+		decoded, err := UnmarshalJSON(data)
+		if err != nil {
+			t.Fatalf("UnmarshalJSON(%s) returned %v", data, err)
+		}
+
+		if !Equal(root, decoded) {
+			t.Fatalf("%s decoded as %s", data, sexprOf(t, decoded))
+		}
+
+		if err := Validate(decoded); err != nil {
+			t.Fatalf("%s decoded as an invalid tree: %v", data, err)
+		}
+	}
+}
+
+// TestJSONEncoding checks the exact encoding of a small tree.
+func TestJSONEncoding(t *testing.T) {
+	root := mustSExpr(t, `(A (B "say \"hi\"") (C (D)))`)
+
+	data, err := MarshalJSON(root)
+	if err != nil {
+		t.Fatalf("MarshalJSON returned %v", err)
+	}
+
+	want := `{"type":"A","children":[{"type":"B","data":"say \"hi\""},{"type":"C","children":[{"type":"D"}]}]}`
+
+	if string(data) != want {
+		t.Fatalf("MarshalJSON = %s, want %s", data, want)
+	}
+
+	_, err = MarshalJSON(nil)
+	if err == nil {
+		t.Fatal("MarshalJSON(nil) succeeded")
+	}
+}
+
+// TestJSONSyntaxErrors checks the offsets of the syntax errors reported for
+// malformed input.
+func TestJSONSyntaxErrors(t *testing.T) {
+	tests := []struct {
+		input  string
+		offset int
+	}{
+		{``, 0},
+		{`[]`, 0},
+		{`{"type":"A"`, 11},
+		{`{"data":"x"}`, 11},
+		{`{"type":1}`, 8},
+		{`{"type":"A","data":[]}`, 19},
+		{`{"type":"A","children":{}}`, 23},
+		{`{"type":"A","children":["x"]}`, 24},
+		{`{"type":"A","extra":"x"}`, 12},
+		{`{"type":"A","span":"x"}`, 19},
+		{`{"type":"A"} {}`, 13},
+		{`{"type" "A"}`, 8},
+		{`{"type":"A",}`, 11},
+	}
+
+	for _, tt := range tests {
+		_, err := UnmarshalJSON([]byte(tt.input))
+
+		var syntax_err *ErrSyntax
+
+		if !errors.As(err, &syntax_err) {
+			t.Errorf("UnmarshalJSON(%q) = %v, want an *ErrSyntax", tt.input, err)
+		} else if syntax_err.Offset != tt.offset || syntax_err.Format != "JSON" {
+			t.Errorf("UnmarshalJSON(%q) = %v, want a JSON error at offset %d", tt.input, err, tt.offset)
+		}
+	}
+}
+
+// TestJSONDepthLimit checks that trees deeper than the nesting limit of
+// encoding/json are rejected with a syntax error.
+func TestJSONDepthLimit(t *testing.T) {
+	root := NewBaseNode("Deep", "")
+
+	leaf := root
+
+	for range 5000 {
+		child := NewBaseNode("Deep", "")
+		_ = leaf.AppendChild(child)
+
+		leaf = child
+	}
+
+	data, _ := MarshalJSON(root)
+
+	_, err := UnmarshalJSON(data)
+
+	var syntax_err *ErrSyntax
+
+	if !errors.As(err, &syntax_err) {
+		t.Fatalf("UnmarshalJSON() of a tree of 5001 nodes = %v, want an *ErrSyntax", err)
+	}
+}
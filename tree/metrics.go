@@ -0,0 +1,173 @@
+package tree
+
+import (
+	common "github.com/PlayerR9/mygo-data/common"
+)
+
+// Depth returns the depth of the node, that is, its number of ancestors.
+//
+// Parameters:
+//   - node: The node.
+//
+// Returns:
+//   - int: The depth of the node. Zero for a root and -1 if the node is nil.
+func Depth[T comparable, D any](node *GenericNode[T, D]) int {
+	if node == nil {
+		return -1
+	}
+
+	var depth int
+
+	for n := node.Parent; n != nil; n = n.Parent {
+		depth++
+	}
+
+	return depth
+}
+
+// Height returns the height of the tree rooted at root, that is, the number of
+// edges on the longest path from the root to a leaf. The computation is not
+// recursive.
+//
+// Parameters:
+//   - root: The root of the tree.
+//
+// Returns:
+//   - int: The height of the tree. Zero for a leaf and -1 if the root is nil.
+func Height[T comparable, D any](root *GenericNode[T, D]) int {
+	if root == nil {
+		return -1
+	}
+
+	var height, depth int
+
+	n := root
+
+	for {
+		if n.FirstChild != nil {
+			n = n.FirstChild
+			depth++
+
+			height = max(height, depth)
+
+			continue
+		}
+
+		for n != root && n.NextSibling == nil {
+			n = n.Parent
+			depth--
+		}
+
+		if n == root {
+			return height
+		}
+
+		n = n.NextSibling
+	}
+}
+
+// Size returns the number of nodes of the tree rooted at root, the root
+// included. The computation is not recursive.
+//
+// Parameters:
+//   - root: The root of the tree.
+//
+// Returns:
+//   - int: The number of nodes. Zero if the root is nil.
+func Size[T comparable, D any](root *GenericNode[T, D]) int {
+	if root == nil {
+		return 0
+	}
+
+	size := 1
+
+	n := root
+
+	for {
+		if n.FirstChild != nil {
+			n = n.FirstChild
+			size++
+
+			continue
+		}
+
+		for n != root && n.NextSibling == nil {
+			n = n.Parent
+		}
+
+		if n == root {
+			return size
+		}
+
+		n = n.NextSibling
+		size++
+	}
+}
+
+// LCA returns the lowest common ancestor of two nodes, that is, the deepest
+// node that is an ancestor of both. A node is considered an ancestor of
+// itself.
+//
+// Parameters:
+//   - a: The first node.
+//   - b: The second node.
+//
+// Returns:
+//   - *GenericNode[T, D]: The lowest common ancestor. Nil if a node is nil or
+//     if the nodes are not in the same tree.
+func LCA[T comparable, D any](a, b *GenericNode[T, D]) *GenericNode[T, D] {
+	if a == nil || b == nil {
+		return nil
+	}
+
+	da, db := Depth(a), Depth(b)
+
+	for ; da > db; da-- {
+		a = a.Parent
+	}
+
+	for ; db > da; db-- {
+		b = b.Parent
+	}
+
+	for a != b {
+		a, b = a.Parent, b.Parent
+	}
+
+	return a
+}
+
+// Path returns the path of child indexes from the root to the node.
+//
+// Parameters:
+//   - root: The root the path starts from.
+//   - node: The node to compute the path of.
+//
+// Returns:
+//   - []int: The path of child indexes. Empty if the node is the root.
+//   - error: An error if the node is not in the tree rooted at root.
+//
+// Errors:
+//   - common.ErrBadParam: If the root or the node is nil or if the node is not
+//     in the tree rooted at root.
+func Path[T comparable, D any](root, node *GenericNode[T, D]) ([]int, error) {
+	if root == nil {
+		err := common.NewErrNilParam("root")
+		return nil, err
+	} else if node == nil {
+		err := common.NewErrNilParam("node")
+		return nil, err
+	}
+
+	if !isAncestorOf(root, node) {
+		err := common.NewErrBadParam("node", "is not in the tree rooted at root")
+		return nil, err
+	}
+
+	path := pathOf(root, node)
+	if path == nil {
+		path = []int{}
+	}
+
+	return path, nil
+}
@@ -10,38 +10,17 @@ type Node interface {
 	fmt.Stringer
 }
 
-// recTreeToString writes a string representation of a tree to the given
-// strings.Builder. The string representation is a recursive stringification
-// of the tree, with each node indented under its parent. The indent string
-// is used to indent each level of the tree.
+// TreeToString takes a tree and returns a string representation of it, with
+// each node on its own line and indented under its parent. The stringification
+// is done using the Node interface, which is implemented by all nodes in the
+// tree. The traversal is not recursive.
 //
-// Parameters:
-//   - b: The strings.Builder to write the string representation to.
-//   - indent: The string to use to indent each level of the tree.
-//   - node: The root of the tree to stringify.
-func recTreeToString[T interface {
-	Children() []T
-
-	Node
-}](b *strings.Builder, indent string, node T) {
-	_, _ = b.WriteString(indent)
-	_, _ = b.WriteString(node.String())
-	_, _ = b.WriteRune('\n')
-
-	children := node.Children()
-
-	for _, child := range children {
-		recTreeToString(b, indent+"   ", child)
-	}
-}
-
-// TreeToString takes a tree and returns a string representation of it. The
-// string representation is a recursive stringification of the tree, with each
-// node indented under its parent. The stringification is done using the
-// TreeNode interface, which is implemented by all nodes in the tree.
+// With WithSpans(true), the source span of each node that has one is
+// appended, as Printer does when its Spans field is true.
 //
 // Parameters:
 //   - root: The root of the tree to stringify.
+//   - opts: The options of the stringification.
 //
 // Returns:
 //   - string: A string representation of the tree.
@@ -49,10 +28,16 @@ func TreeToString[T interface {
 	Children() []T
 
 	Node
-}](root T) string {
+}](root T, opts ...EncodeOption) string {
+	o := newEncodeOptions(false, opts)
+
 	var builder strings.Builder
 
-	recTreeToString(&builder, "", root)
+	p := Printer[T]{
+		Spans: o.spans,
+	}
+
+	_ = p.Fprint(&builder, root) // strings.Builder never fails.
 
 	return builder.String()
 }
@@ -0,0 +1,127 @@
+package tree
+
+import (
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+// textParser holds the state shared by the parsers of the small languages of
+// this package.
+type textParser struct {
+	// format is the name of the language, used in the errors.
+	format string
+
+	// input is the input being parsed.
+	input string
+
+	// pos is the current byte offset in the input.
+	pos int
+}
+
+// errorf returns a syntax error at the current position.
+//
+// Parameters:
+//   - reason: The reason the input is not valid.
+//
+// Returns:
+//   - error: An instance of ErrSyntax. Never returns nil.
+func (p *textParser) errorf(reason string) error {
+	if p.pos >= len(p.input) {
+		reason = "unexpected end of " + p.format + "; " + reason
+	}
+
+	err := NewErrSyntax(p.format, p.pos, reason)
+	return err
+}
+
+// peek returns the rune at the current position.
+//
+// Returns:
+//   - rune: The rune at the current position. utf8.RuneError at the end of
+//     the input.
+func (p *textParser) peek() rune {
+	r, _ := utf8.DecodeRuneInString(p.input[p.pos:])
+	return r
+}
+
+// skipSpace skips whitespace.
+//
+// Returns:
+//   - bool: True if any whitespace was skipped, false otherwise.
+func (p *textParser) skipSpace() bool {
+	start := p.pos
+
+	for p.pos < len(p.input) {
+		r, size := utf8.DecodeRuneInString(p.input[p.pos:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+
+		p.pos += size
+	}
+
+	return p.pos > start
+}
+
+// isIdentRune checks whether the rune can be part of an identifier.
+//
+// Parameters:
+//   - r: The rune to check.
+//
+// Returns:
+//   - bool: True if the rune can be part of an identifier, false otherwise.
+func isIdentRune(r rune) bool {
+	return r == '_' || r == '-' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// readIdent reads an identifier at the current position.
+//
+// Returns:
+//   - string: The identifier. Empty if there is none.
+func (p *textParser) readIdent() string {
+	start := p.pos
+
+	for p.pos < len(p.input) {
+		r, size := utf8.DecodeRuneInString(p.input[p.pos:])
+		if !isIdentRune(r) {
+			break
+		}
+
+		p.pos += size
+	}
+
+	return p.input[start:p.pos]
+}
+
+// readString reads a quoted string at the current position.
+//
+// Returns:
+//   - string: The unquoted string.
+//   - error: An error if the string is not valid.
+//
+// Errors:
+//   - *ErrSyntax: If the string is not terminated or is not a valid quoted string.
+func (p *textParser) readString() (string, error) {
+	start := p.pos
+
+	for i := start + 1; i < len(p.input); i++ {
+		switch p.input[i] {
+		case '\\':
+			i++
+		case '"':
+			str, err := strconv.Unquote(p.input[start : i+1])
+			if err != nil {
+				err := NewErrSyntax(p.format, start, "invalid quoted string")
+				return "", err
+			}
+
+			p.pos = i + 1
+
+			return str, nil
+		}
+	}
+
+	err := NewErrSyntax(p.format, start, "unterminated string")
+	return "", err
+}
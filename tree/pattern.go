@@ -0,0 +1,598 @@
+package tree
+
+import (
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// patternNode is a compiled pattern that matches a single node.
+type patternNode struct {
+	// types are the accepted types. Any type is accepted if empty.
+	types []string
+
+	// attrs are the attribute filters.
+	attrs []attrFilter
+
+	// exact is true if the children must match children exactly. If false,
+	// the children are not checked.
+	exact bool
+
+	// children are the patterns of the children. Only used if exact is true.
+	children []patternElem
+
+	// capture is the name under which the node is captured. Empty if the node
+	// is not captured.
+	capture string
+}
+
+// patternElem is a pattern of a child list element, with its repetition.
+type patternElem struct {
+	// node is the pattern each repeated child must match.
+	node *patternNode
+
+	// min is the minimum number of repetitions.
+	min int
+
+	// max is the maximum number of repetitions. Negative if unbounded.
+	max int
+}
+
+// Pattern is a compiled tree pattern. See CompilePattern for the syntax.
+type Pattern struct {
+	// src is the source of the pattern.
+	src string
+
+	// root is the compiled pattern.
+	root *patternNode
+}
+
+// String implements fmt.Stringer.
+func (p Pattern) String() string {
+	return p.src
+}
+
+// CompilePattern compiles a tree pattern. Patterns are S-expressions:
+//
+//	(BinaryOp[data="+"] ?lhs (Number[data="0"]))
+//
+// Where:
+//   - (<head> <element> ...) matches a node whose type and data match <head>
+//     and whose children match the elements exactly, in order.
+//   - <head> alone, without parentheses, matches a node whatever its children.
+//   - <head> is a type, '*' for any type or alternatives such as
+//     "BinaryOp|UnaryOp", followed by any number of [data<op>"value"] or
+//     [type<op>"value"] filters, as in CompileSelector. Types that are not
+//     made of letters, digits, '_', '-' and '.' must be quoted.
+//   - _ matches any node.
+//   - ?name captures the node under the given name; ?name:<pattern> captures a
+//     node that matches <pattern>.
+//   - An element immediately followed by '*', '+' or '?' is repeated zero or
+//     more times, one or more times or at most once. Repetitions are greedy
+//     and backtrack; the backtracking is memoized, so matching a child list
+//     takes polynomial time. A repeated capture captures every repeated node.
+//   - ... matches any number of children, like _*.
+//
+// Parameters:
+//   - src: The source of the pattern.
+//
+// Returns:
+//   - *Pattern: The compiled pattern. Nil if an error occurred.
+//   - error: An error if the pattern is not valid.
+//
+// Errors:
+//   - *ErrSyntax: If the pattern is not valid.
+func CompilePattern(src string) (*Pattern, error) {
+	p := &textParser{format: "pattern", input: src}
+
+	p.skipSpace()
+
+	root, err := p.parsePattern()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+
+	if p.pos < len(p.input) {
+		err := p.errorf("unexpected character " + strconv.QuoteRune(p.peek()))
+		return nil, err
+	}
+
+	pat := &Pattern{
+		src:  src,
+		root: root,
+	}
+
+	return pat, nil
+}
+
+// parsePattern parses a pattern at the current position.
+//
+// Returns:
+//   - *patternNode: The pattern.
+//   - error: An error if the pattern is not valid.
+//
+// Errors:
+//   - *ErrSyntax: If the pattern is not valid.
+func (p *textParser) parsePattern() (*patternNode, error) {
+	if p.pos >= len(p.input) {
+		err := p.errorf("expected a pattern")
+		return nil, err
+	}
+
+	switch p.input[p.pos] {
+	case '?':
+		start := p.pos
+
+		p.pos++
+
+		name := p.readIdent()
+		if name == "" {
+			err := p.errorf("expected a capture name")
+			return nil, err
+		}
+
+		if p.pos >= len(p.input) || p.input[p.pos] != ':' {
+			node := &patternNode{capture: name}
+			return node, nil
+		}
+
+		p.pos++
+
+		node, err := p.parsePattern()
+		if err != nil {
+			return nil, err
+		}
+
+		if node.capture != "" {
+			p.pos = start
+
+			err := p.errorf("a node cannot be captured twice")
+			return nil, err
+		}
+
+		node.capture = name
+
+		return node, nil
+	case '(':
+		p.pos++
+
+		p.skipSpace()
+
+		node, err := p.parseHead()
+		if err != nil {
+			return nil, err
+		}
+
+		node.exact = true
+
+		for {
+			p.skipSpace()
+
+			if p.pos >= len(p.input) {
+				err := p.errorf("expected ')'")
+				return nil, err
+			}
+
+			if p.input[p.pos] == ')' {
+				p.pos++
+				break
+			}
+
+			elem, err := p.parseElem()
+			if err != nil {
+				return nil, err
+			}
+
+			node.children = append(node.children, elem)
+		}
+
+		return node, nil
+	default:
+		if p.input[p.pos] == '_' && (p.pos+1 == len(p.input) || !isIdentRune(rune(p.input[p.pos+1]))) {
+			p.pos++
+
+			return &patternNode{}, nil
+		}
+
+		node, err := p.parseHead()
+		return node, err
+	}
+}
+
+// parseHead parses the types and filters of a node pattern at the current
+// position.
+//
+// Returns:
+//   - *patternNode: The pattern, without children.
+//   - error: An error if the head is not valid.
+//
+// Errors:
+//   - *ErrSyntax: If the head is not valid.
+func (p *textParser) parseHead() (*patternNode, error) {
+	node := &patternNode{}
+
+	if p.peek() == '*' {
+		p.pos++
+	} else {
+		for {
+			var type_ string
+
+			if p.peek() == '"' {
+				str, err := p.readString()
+				if err != nil {
+					return nil, err
+				}
+
+				type_ = str
+			} else {
+				type_ = p.readIdent()
+				if type_ == "" {
+					err := p.errorf("expected a node type")
+					return nil, err
+				}
+			}
+
+			node.types = append(node.types, type_)
+
+			if p.pos >= len(p.input) || p.input[p.pos] != '|' {
+				break
+			}
+
+			p.pos++
+		}
+	}
+
+	for p.pos < len(p.input) && p.input[p.pos] == '[' {
+		attr, err := p.parseAttr()
+		if err != nil {
+			return nil, err
+		}
+
+		node.attrs = append(node.attrs, attr)
+	}
+
+	return node, nil
+}
+
+// parseElem parses an element of a child list at the current position.
+//
+// Returns:
+//   - patternElem: The element.
+//   - error: An error if the element is not valid.
+//
+// Errors:
+//   - *ErrSyntax: If the element is not valid.
+func (p *textParser) parseElem() (patternElem, error) {
+	if strings.HasPrefix(p.input[p.pos:], "...") {
+		p.pos += 3
+
+		elem := patternElem{
+			node: &patternNode{},
+			min:  0,
+			max:  -1,
+		}
+
+		return elem, nil
+	}
+
+	node, err := p.parsePattern()
+	if err != nil {
+		return patternElem{}, err
+	}
+
+	elem := patternElem{
+		node: node,
+		min:  1,
+		max:  1,
+	}
+
+	if p.pos >= len(p.input) {
+		return elem, nil
+	}
+
+	switch p.input[p.pos] {
+	case '*':
+		elem.min, elem.max = 0, -1
+	case '+':
+		elem.min, elem.max = 1, -1
+	case '?':
+		elem.min, elem.max = 0, 1
+	default:
+		return elem, nil
+	}
+
+	p.pos++
+
+	return elem, nil
+}
+
+// capture is a node captured during a match.
+type capture[T any] struct {
+	// name is the name of the capture.
+	name string
+
+	// node is the captured node.
+	node T
+}
+
+// patternMatcher holds the state of a match.
+type patternMatcher[T interface {
+	Children() []T
+}] struct {
+	// label returns the type and the data of a node.
+	label func(node T) (string, string)
+
+	// captures are the nodes captured so far.
+	captures []capture[T]
+}
+
+// matchNode checks whether the node matches the pattern. On failure, the
+// captures are left unchanged. The recursion is bounded by the depth of the
+// pattern.
+//
+// Parameters:
+//   - pat: The pattern.
+//   - node: The node to check.
+//
+// Returns:
+//   - bool: True if the node matches, false otherwise.
+func (m *patternMatcher[T]) matchNode(pat *patternNode, node T) bool {
+	type_, data := m.label(node)
+
+	if len(pat.types) > 0 && !slices.Contains(pat.types, type_) {
+		return false
+	}
+
+	for _, attr := range pat.attrs {
+		if !attr.match(type_, data) {
+			return false
+		}
+	}
+
+	mark := len(m.captures)
+
+	if pat.capture != "" {
+		m.captures = append(m.captures, capture[T]{name: pat.capture, node: node})
+	}
+
+	if pat.exact && !m.matchSeq(pat.children, node.Children()) {
+		m.captures = m.captures[:mark]
+		return false
+	}
+
+	return true
+}
+
+// nodeMatch is the memoized result of matching a child against the node
+// pattern of an element.
+type nodeMatch[T any] struct {
+	// done is true once the child was matched.
+	done bool
+
+	// ok is true if the child matches.
+	ok bool
+
+	// captures are the captures made by the match.
+	captures []capture[T]
+}
+
+// seqMemo memoizes the matching of a child list, so that backtracking over
+// repetitions takes polynomial time.
+type seqMemo[T any] struct {
+	// elems are the elements.
+	elems []patternElem
+
+	// children are the children to check.
+	children []T
+
+	// failed[i*(len(children)+1)+c] is true if elems[i:] is known not to
+	// match children[c:].
+	failed []bool
+
+	// nodes[i*len(children)+c] is the result of matching children[c] against
+	// the node pattern of elems[i].
+	nodes []nodeMatch[T]
+}
+
+// matchSeq checks whether the children match the elements. On failure, the
+// captures are left unchanged.
+//
+// Parameters:
+//   - elems: The elements.
+//   - children: The children to check.
+//
+// Returns:
+//   - bool: True if the children match, false otherwise.
+func (m *patternMatcher[T]) matchSeq(elems []patternElem, children []T) bool {
+	if len(elems) == 0 {
+		return len(children) == 0
+	}
+
+	s := &seqMemo[T]{
+		elems:    elems,
+		children: children,
+		failed:   make([]bool, len(elems)*(len(children)+1)),
+		nodes:    make([]nodeMatch[T], len(elems)*len(children)),
+	}
+
+	ok := m.matchSeqFrom(s, 0, 0)
+	return ok
+}
+
+// matchChild checks whether the child matches the node pattern of the
+// element. On success, the captures of the match are appended.
+//
+// Parameters:
+//   - s: The memo of the child list.
+//   - i: The index of the element.
+//   - c: The index of the child.
+//
+// Returns:
+//   - bool: True if the child matches, false otherwise.
+func (m *patternMatcher[T]) matchChild(s *seqMemo[T], i, c int) bool {
+	res := &s.nodes[i*len(s.children)+c]
+
+	if !res.done {
+		mark := len(m.captures)
+
+		res.done = true
+		res.ok = m.matchNode(s.elems[i].node, s.children[c])
+		res.captures = slices.Clone(m.captures[mark:])
+
+		return res.ok
+	}
+
+	if res.ok {
+		m.captures = append(m.captures, res.captures...)
+	}
+
+	return res.ok
+}
+
+// matchSeqFrom checks whether children[c:] match elems[i:]. On failure, the
+// captures are left unchanged. The recursion is bounded by the number of
+// elements.
+//
+// Parameters:
+//   - s: The memo of the child list.
+//   - i: The index of the first element.
+//   - c: The index of the first child.
+//
+// Returns:
+//   - bool: True if the children match, false otherwise.
+func (m *patternMatcher[T]) matchSeqFrom(s *seqMemo[T], i, c int) bool {
+	if i == len(s.elems) {
+		return c == len(s.children)
+	}
+
+	key := i*(len(s.children)+1) + c
+	if s.failed[key] {
+		return false
+	}
+
+	elem := s.elems[i]
+
+	// marks[r] is the number of captures after r repetitions.
+	marks := []int{len(m.captures)}
+
+	for k := c; k < len(s.children) && (elem.max < 0 || k-c < elem.max); k++ {
+		if !m.matchChild(s, i, k) {
+			break
+		}
+
+		marks = append(marks, len(m.captures))
+	}
+
+	for r := len(marks) - 1; r >= elem.min; r-- {
+		m.captures = m.captures[:marks[r]]
+
+		if m.matchSeqFrom(s, i+1, c+r) {
+			return true
+		}
+	}
+
+	m.captures = m.captures[:marks[0]]
+	s.failed[key] = true
+
+	return false
+}
+
+// MatchFunc checks whether the node matches the pattern, for any kind of node.
+//
+// Parameters:
+//   - pat: The pattern.
+//   - node: The node to check.
+//   - label: The function that returns the type and the data of a node.
+//
+// Returns:
+//   - map[string][]T: The captured nodes by name, in the order they were
+//     captured. Nil if the node does not match or nothing was captured.
+//   - bool: True if the node matches, false otherwise. False if the pattern or
+//     the label function is nil.
+func MatchFunc[T interface {
+	Children() []T
+}](pat *Pattern, node T, label func(node T) (string, string)) (map[string][]T, bool) {
+	if pat == nil || label == nil {
+		return nil, false
+	}
+
+	m := &patternMatcher[T]{
+		label: label,
+	}
+
+	if !m.matchNode(pat.root, node) {
+		return nil, false
+	}
+
+	if len(m.captures) == 0 {
+		return nil, true
+	}
+
+	captures := make(map[string][]T)
+
+	for _, c := range m.captures {
+		captures[c.name] = append(captures[c.name], c.node)
+	}
+
+	return captures, true
+}
+
+// baseNodeLabel returns the type and the data of the node.
+//
+// Parameters:
+//   - node: The node.
+//
+// Returns:
+//   - string: The type of the node.
+//   - string: The data of the node.
+func baseNodeLabel(node *BaseNode) (string, string) {
+	return node.Type, node.Data
+}
+
+// Match checks whether the node matches the pattern.
+//
+// Parameters:
+//   - node: The node to check.
+//
+// Returns:
+//   - map[string][]*BaseNode: The captured nodes by name, in the order they
+//     were captured. Nil if the node does not match or nothing was captured.
+//   - bool: True if the node matches, false otherwise. False if the receiver or
+//     the node is nil.
+func (p *Pattern) Match(node *BaseNode) (map[string][]*BaseNode, bool) {
+	if p == nil || node == nil {
+		return nil, false
+	}
+
+	captures, ok := MatchFunc(p, node, baseNodeLabel)
+	return captures, ok
+}
+
+// FindAll returns an iterator over the nodes of the tree rooted at root that
+// match the pattern, in pre-order, together with their captures.
+//
+// Parameters:
+//   - root: The root of the tree to search.
+//
+// Returns:
+//   - iter.Seq2[*BaseNode, map[string][]*BaseNode]: An iterator over the
+//     matching nodes and their captures. Never returns nil.
+func (p *Pattern) FindAll(root *BaseNode) iter.Seq2[*BaseNode, map[string][]*BaseNode] {
+	if p == nil || root == nil {
+		return func(yield func(*BaseNode, map[string][]*BaseNode) bool) {}
+	}
+
+	fn := func(yield func(*BaseNode, map[string][]*BaseNode) bool) {
+		for node := range PreOrder(root) {
+			captures, ok := MatchFunc(p, node, baseNodeLabel)
+			if ok && !yield(node, captures) {
+				return
+			}
+		}
+	}
+
+	return fn
+}
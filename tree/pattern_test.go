@@ -0,0 +1,180 @@
+package tree
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// mustPattern compiles the pattern or fails the test.
+//
+// Parameters:
+//   - t: The test.
+//   - src: The source of the pattern.
+//
+// Returns:
+//   - *Pattern: The compiled pattern. Never returns nil.
+func mustPattern(t *testing.T, src string) *Pattern {
+	t.Helper()
+
+	pat, err := CompilePattern(src)
+	if err != nil {
+		t.Fatalf("CompilePattern(%q) returned %v", src, err)
+	}
+
+	return pat
+}
+
+// capturedData returns the data of the nodes captured under the name.
+//
+// Parameters:
+//   - captures: The captures of a match.
+//   - name: The name of the capture.
+//
+// Returns:
+//   - []string: The data of the captured nodes, in order.
+func capturedData(captures map[string][]*BaseNode, name string) []string {
+	var data []string
+
+	for _, n := range captures[name] {
+		data = append(data, n.Data)
+	}
+
+	return data
+}
+
+// TestPatternRepetition checks the '*', '+', '?' and '...' repetitions.
+func TestPatternRepetition(t *testing.T) {
+	tests := []struct {
+		pattern string
+		tree    string
+		want    bool
+	}{
+		{`(L A*)`, `(L)`, true},
+		{`(L A*)`, `(L (A) (A) (A))`, true},
+		{`(L A*)`, `(L (A) (B))`, false},
+		{`(L A+)`, `(L)`, false},
+		{`(L A+ B)`, `(L (A) (A) (B))`, true},
+		{`(L A? B)`, `(L (B))`, true},
+		{`(L A? B)`, `(L (A) (A) (B))`, false},
+		{`(L ... B)`, `(L (A) (C) (B))`, true},
+		{`(L ... B ...)`, `(L (A) (C))`, false},
+		{`(L _ _)`, `(L (A) (B) (C))`, false},
+		{`L`, `(L (A) (B) (C))`, true},
+	}
+
+	for _, tt := range tests {
+		pat := mustPattern(t, tt.pattern)
+
+		_, ok := pat.Match(mustSExpr(t, tt.tree))
+		if ok != tt.want {
+			t.Errorf("%s on %s = %t, want %t", tt.pattern, tt.tree, ok, tt.want)
+		}
+	}
+}
+
+// TestPatternCaptures checks single, nested and repeated captures.
+func TestPatternCaptures(t *testing.T) {
+	pat := mustPattern(t, `(Call ?fn:Ident ?args:(Arg ?val)*)`)
+	root := mustSExpr(t, `(Call (Ident "f") (Arg (Num "1")) (Arg (Num "2")))`)
+
+	captures, ok := pat.Match(root)
+	if !ok {
+		t.Fatalf("%s does not match", pat)
+	}
+
+	if got := capturedData(captures, "fn"); !slices.Equal(got, []string{"f"}) {
+		t.Errorf("fn = %v", got)
+	}
+
+	if got := len(captures["args"]); got != 2 {
+		t.Errorf("args has %d nodes, want 2", got)
+	}
+
+	if got := capturedData(captures, "val"); !slices.Equal(got, []string{"1", "2"}) {
+		t.Errorf("val = %v", got)
+	}
+
+	_, ok = pat.Match(mustSExpr(t, `(Call (Num "1"))`))
+	if ok {
+		t.Errorf("%s matches a call without an identifier", pat)
+	}
+}
+
+// TestPatternBacktracking checks that a greedy repetition gives back children
+// to the following elements and that the captures of the abandoned attempts
+// are discarded.
+func TestPatternBacktracking(t *testing.T) {
+	pat := mustPattern(t, `(L ?xs:A* ?last:A B)`)
+	root := mustSExpr(t, `(L (A "1") (A "2") (A "3") (B))`)
+
+	captures, ok := pat.Match(root)
+	if !ok {
+		t.Fatalf("%s does not match", pat)
+	}
+
+	if got := capturedData(captures, "xs"); !slices.Equal(got, []string{"1", "2"}) {
+		t.Errorf("xs = %v, want [1 2]", got)
+	}
+
+	if got := capturedData(captures, "last"); !slices.Equal(got, []string{"3"}) {
+		t.Errorf("last = %v, want [3]", got)
+	}
+
+	pat = mustPattern(t, `(L ?a:_* ?b:_+ ?c:_)`)
+	root = mustSExpr(t, `(L (X "1") (X "2") (X "3") (X "4"))`)
+
+	captures, ok = pat.Match(root)
+	if !ok {
+		t.Fatalf("%s does not match", pat)
+	}
+
+	if got := capturedData(captures, "a"); !slices.Equal(got, []string{"1", "2"}) {
+		t.Errorf("a = %v, want [1 2]", got)
+	}
+
+	if got := capturedData(captures, "b"); !slices.Equal(got, []string{"3"}) {
+		t.Errorf("b = %v, want [3]", got)
+	}
+}
+
+// TestPatternWideNode checks that a pattern with many adjacent repetitions
+// fails in polynomial time on a wide node, which takes exponential time without
+// memoization.
+func TestPatternWideNode(t *testing.T) {
+	pat := mustPattern(t, `(X _* _* _* _* _* _* _* _* Y)`)
+
+	var builder strings.Builder
+
+	_, _ = builder.WriteString("(X")
+
+	for range 200 {
+		_, _ = builder.WriteString(" (Z)")
+	}
+
+	_, _ = builder.WriteString(")")
+
+	_, ok := pat.Match(mustSExpr(t, builder.String()))
+	if ok {
+		t.Fatalf("%s matches a node without a Y child", pat)
+	}
+}
+
+// TestPatternFindAll checks that FindAll yields the matching nodes in
+// pre-order.
+func TestPatternFindAll(t *testing.T) {
+	pat := mustPattern(t, `(Add ?x ?y)`)
+	root := mustSExpr(t, `(Add (Add (Num "1") (Num "2")) (Mul (Add (Num "3") (Num "4"))))`)
+
+	var firsts []string
+
+	for _, captures := range pat.FindAll(root) {
+		firsts = append(firsts, captures["x"][0].Type+captures["x"][0].Data)
+	}
+
+	want := []string{"Add", "Num1", "Num3"}
+
+	if !slices.Equal(firsts, want) {
+		t.Fatalf("FindAll captured %v, want %v", firsts, want)
+	}
+}
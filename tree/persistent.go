@@ -0,0 +1,486 @@
+package tree
+
+import (
+	"slices"
+
+	common "github.com/PlayerR9/mygo-data/common"
+)
+
+// PersistentNode is an immutable tree node. Edits do not modify the tree but
+// return a new root that shares every untouched subtree with the old one, so
+// that old versions of a tree can be kept around cheaply. The zero value is
+// a leaf with the zero type and data.
+//
+// Since nodes are shared between versions, a node has no link to its parent;
+// nodes are addressed by paths of child indexes from a root instead.
+type PersistentNode[T comparable, D any] struct {
+	// type_ is the type of the node.
+	type_ T
+
+	// data is the data associated with the node.
+	data D
+
+	// span is the source span of the node. Nil if unknown.
+	span *Span
+
+	// children are the children of the node. Never modified once the node is
+	// created.
+	children []*PersistentNode[T, D]
+}
+
+// NewPersistentNode creates a new PersistentNode with the specified type, data
+// and children. Nil children are ignored.
+//
+// Parameters:
+//   - type_: The type of the node.
+//   - data: The data associated with the node.
+//   - children: The children of the node, in order.
+//
+// Returns:
+//   - *PersistentNode[T, D]: The new node. Never returns nil.
+func NewPersistentNode[T comparable, D any](type_ T, data D, children ...*PersistentNode[T, D]) *PersistentNode[T, D] {
+	n := &PersistentNode[T, D]{
+		type_: type_,
+		data:  data,
+	}
+
+	for _, c := range children {
+		if c != nil {
+			n.children = append(n.children, c)
+		}
+	}
+
+	return n
+}
+
+// String implements Node.
+//
+// Format:
+//
+//	"Node[<type> (<data>)]"
+//
+// Where:
+//   - <type> is the type of the node.
+//   - (<data>) is the data of the node. It is omitted if the data is nil or
+//     an empty string. String data is quoted.
+func (n PersistentNode[T, D]) String() string {
+	str := nodeString(n.type_, n.data)
+	return str
+}
+
+// Type returns the type of the node.
+//
+// Returns:
+//   - T: The type of the node.
+func (n PersistentNode[T, D]) Type() T {
+	return n.type_
+}
+
+// Data returns the data of the node.
+//
+// Returns:
+//   - D: The data of the node.
+func (n PersistentNode[T, D]) Data() D {
+	return n.data
+}
+
+// Span returns the source span of the node.
+//
+// Returns:
+//   - *Span: A copy of the span of the node. Nil if the node has none.
+func (n PersistentNode[T, D]) Span() *Span {
+	span := copySpan(n.span)
+	return span
+}
+
+// sourceSpan implements spanner.
+func (n *PersistentNode[T, D]) sourceSpan() *Span {
+	if n == nil {
+		return nil
+	}
+
+	return n.span
+}
+
+// Len returns the number of children of the node.
+//
+// Returns:
+//   - int: The number of children.
+func (n PersistentNode[T, D]) Len() int {
+	return len(n.children)
+}
+
+// Child returns the child at the given index.
+//
+// Parameters:
+//   - idx: The index of the child, starting from 0.
+//
+// Returns:
+//   - *PersistentNode[T, D]: The child. Nil if the index is out of range.
+func (n PersistentNode[T, D]) Child(idx int) *PersistentNode[T, D] {
+	if idx < 0 || idx >= len(n.children) {
+		return nil
+	}
+
+	return n.children[idx]
+}
+
+// Children returns the children of the node.
+//
+// Returns:
+//   - []*PersistentNode[T, D]: A copy of the children of the node. Nil if the
+//     node has no children.
+func (n PersistentNode[T, D]) Children() []*PersistentNode[T, D] {
+	if len(n.children) == 0 {
+		return nil
+	}
+
+	children := slices.Clone(n.children)
+	return children
+}
+
+// At returns the node at the given path of child indexes from the receiver.
+//
+// Parameters:
+//   - path: The path of child indexes. If empty, the receiver is returned.
+//
+// Returns:
+//   - *PersistentNode[T, D]: The node at the path. Nil if there is none.
+func (n *PersistentNode[T, D]) At(path []int) *PersistentNode[T, D] {
+	curr := n
+
+	for _, idx := range path {
+		if curr == nil {
+			return nil
+		}
+
+		curr = curr.Child(idx)
+	}
+
+	return curr
+}
+
+// WithData returns a copy of the node with the given data. The copy shares
+// the children of the receiver.
+//
+// Parameters:
+//   - data: The data of the copy.
+//
+// Returns:
+//   - *PersistentNode[T, D]: The copy. Never returns nil.
+func (n PersistentNode[T, D]) WithData(data D) *PersistentNode[T, D] {
+	n.data = data
+	return &n
+}
+
+// WithSpan returns a copy of the node with the given span. The copy shares the
+// children of the receiver.
+//
+// Parameters:
+//   - span: The span of the copy. It is copied.
+//
+// Returns:
+//   - *PersistentNode[T, D]: The copy. Never returns nil.
+func (n PersistentNode[T, D]) WithSpan(span *Span) *PersistentNode[T, D] {
+	n.span = copySpan(span)
+	return &n
+}
+
+// edit returns a new version of the tree rooted at the receiver where the
+// children of the node at the given path are replaced by the result of fn.
+// The ancestors of that node are copied and every other node is shared.
+//
+// Parameters:
+//   - path: The path of the node whose children are edited.
+//   - fn: The function that returns the new children. It must not modify the
+//     slice it is given.
+//
+// Returns:
+//   - *PersistentNode[T, D]: The new root.
+//   - error: An error if the path does not exist or if fn failed.
+//
+// Errors:
+//   - common.ErrBadParam: If the path does not exist.
+//   - any other error: Returned by fn.
+func (n *PersistentNode[T, D]) edit(path []int, fn func(children []*PersistentNode[T, D]) ([]*PersistentNode[T, D], error)) (*PersistentNode[T, D], error) {
+	nodes := []*PersistentNode[T, D]{n}
+
+	for i, idx := range path {
+		child := nodes[i].Child(idx)
+		if child == nil {
+			err := common.NewErrBadParam("path", "refers to a missing child at "+pathString(path[:i+1]))
+			return nil, err
+		}
+
+		nodes = append(nodes, child)
+	}
+
+	target := nodes[len(nodes)-1]
+
+	children, err := fn(target.children)
+	if err != nil {
+		return nil, err
+	}
+
+	copied := *target
+	copied.children = children
+
+	new_node := &copied
+
+	for i := len(path) - 1; i >= 0; i-- {
+		parent := *nodes[i]
+
+		parent.children = slices.Clone(parent.children)
+		parent.children[path[i]] = new_node
+
+		new_node = &parent
+	}
+
+	return new_node, nil
+}
+
+// splitPath splits a non-empty path into the path of the parent and the
+// index of the last step.
+//
+// Parameters:
+//   - path: The path to split.
+//
+// Returns:
+//   - []int: The path of the parent.
+//   - int: The index of the last step.
+//   - error: An error if the path is empty.
+//
+// Errors:
+//   - common.ErrBadParam: If the path is empty.
+func splitPath(path []int) ([]int, int, error) {
+	if len(path) == 0 {
+		err := common.NewErrBadParam("path", "must not be empty")
+		return nil, 0, err
+	}
+
+	return path[:len(path)-1], path[len(path)-1], nil
+}
+
+// ReplaceAt returns a new version of the tree rooted at the receiver where the
+// node at the given path is replaced with the given node. The receiver is not
+// modified.
+//
+// Parameters:
+//   - path: The path of the node to replace. If empty, the root is replaced.
+//   - node: The node that replaces the node at the path.
+//
+// Returns:
+//   - *PersistentNode[T, D]: The new root. Nil if an error occurred.
+//   - error: An error if the node could not be replaced.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If the node is nil or if the path does not exist.
+func (n *PersistentNode[T, D]) ReplaceAt(path []int, node *PersistentNode[T, D]) (*PersistentNode[T, D], error) {
+	if n == nil {
+		return nil, common.ErrNilReceiver
+	} else if node == nil {
+		err := common.NewErrNilParam("node")
+		return nil, err
+	}
+
+	if len(path) == 0 {
+		return node, nil
+	}
+
+	parent_path, idx, _ := splitPath(path)
+
+	root, err := n.edit(parent_path, func(children []*PersistentNode[T, D]) ([]*PersistentNode[T, D], error) {
+		if idx < 0 || idx >= len(children) {
+			err := common.NewErrBadParam("path", "refers to a missing child at "+pathString(path))
+			return nil, err
+		}
+
+		children = slices.Clone(children)
+		children[idx] = node
+
+		return children, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return root, nil
+}
+
+// InsertAt returns a new version of the tree rooted at the receiver where the
+// given node is inserted at the given path: the last index of the path is the
+// position of the node among its new siblings. The receiver is not modified.
+//
+// Parameters:
+//   - path: The path the node will have once inserted. Its last index may be
+//     equal to the number of children to append the node.
+//   - node: The node to insert.
+//
+// Returns:
+//   - *PersistentNode[T, D]: The new root. Nil if an error occurred.
+//   - error: An error if the node could not be inserted.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If the node is nil, if the path is empty or if it
+//     does not exist.
+func (n *PersistentNode[T, D]) InsertAt(path []int, node *PersistentNode[T, D]) (*PersistentNode[T, D], error) {
+	if n == nil {
+		return nil, common.ErrNilReceiver
+	} else if node == nil {
+		err := common.NewErrNilParam("node")
+		return nil, err
+	}
+
+	parent_path, idx, err := splitPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := n.edit(parent_path, func(children []*PersistentNode[T, D]) ([]*PersistentNode[T, D], error) {
+		if idx < 0 || idx > len(children) {
+			err := common.NewErrBadParam("path", "has an out of range index at "+pathString(path))
+			return nil, err
+		}
+
+		children = slices.Insert(slices.Clone(children), idx, node)
+
+		return children, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return root, nil
+}
+
+// DeleteAt returns a new version of the tree rooted at the receiver where the
+// node at the given path, with its subtree, is removed. The receiver is not
+// modified.
+//
+// Parameters:
+//   - path: The path of the node to remove.
+//
+// Returns:
+//   - *PersistentNode[T, D]: The new root. Nil if an error occurred.
+//   - error: An error if the node could not be removed.
+//
+// Errors:
+//   - common.ErrNilReceiver: If the receiver is nil.
+//   - common.ErrBadParam: If the path is empty or does not exist.
+func (n *PersistentNode[T, D]) DeleteAt(path []int) (*PersistentNode[T, D], error) {
+	if n == nil {
+		return nil, common.ErrNilReceiver
+	}
+
+	parent_path, idx, err := splitPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := n.edit(parent_path, func(children []*PersistentNode[T, D]) ([]*PersistentNode[T, D], error) {
+		if idx < 0 || idx >= len(children) {
+			err := common.NewErrBadParam("path", "refers to a missing child at "+pathString(path))
+			return nil, err
+		}
+
+		children = slices.Delete(slices.Clone(children), idx, idx+1)
+		if len(children) == 0 {
+			children = nil
+		}
+
+		return children, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return root, nil
+}
+
+// Persist converts the tree rooted at root into a persistent tree. The spans
+// are copied. The conversion is not recursive.
+//
+// Parameters:
+//   - root: The root of the tree to convert.
+//
+// Returns:
+//   - *PersistentNode[T, D]: The root of the persistent tree. Nil if the root
+//     is nil.
+func Persist[T comparable, D any](root *GenericNode[T, D]) *PersistentNode[T, D] {
+	if root == nil {
+		return nil
+	}
+
+	// PostOrder yields the children right before their parent, so their
+	// conversions are on top of the stack.
+	var stack []*PersistentNode[T, D]
+
+	for n := range PostOrder(root) {
+		var count int
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			count++
+		}
+
+		p := &PersistentNode[T, D]{
+			type_: n.Type,
+			data:  n.Data,
+			span:  copySpan(n.Span),
+		}
+
+		if count > 0 {
+			p.children = slices.Clone(stack[len(stack)-count:])
+		}
+
+		stack = stack[:len(stack)-count]
+		stack = append(stack, p)
+	}
+
+	return stack[0]
+}
+
+// ToNode converts the tree rooted at the receiver into a new mutable tree. The
+// spans are copied. The conversion is not recursive.
+//
+// Returns:
+//   - *GenericNode[T, D]: The root of the new tree. Nil if the receiver is nil.
+func (n *PersistentNode[T, D]) ToNode() *GenericNode[T, D] {
+	if n == nil {
+		return nil
+	}
+
+	type frame struct {
+		// node is the node to convert.
+		node *PersistentNode[T, D]
+
+		// parent is the conversion of the node's parent.
+		parent *GenericNode[T, D]
+	}
+
+	root := NewGenericNode(n.type_, n.data)
+	root.Span = copySpan(n.span)
+
+	var stack []frame
+
+	for i := len(n.children) - 1; i >= 0; i-- {
+		stack = append(stack, frame{node: n.children[i], parent: root})
+	}
+
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		c := NewGenericNode(top.node.type_, top.node.data)
+		c.Span = copySpan(top.node.span)
+
+		_ = top.parent.AppendChild(c)
+
+		for i := len(top.node.children) - 1; i >= 0; i-- {
+			stack = append(stack, frame{node: top.node.children[i], parent: c})
+		}
+	}
+
+	return root
+}
@@ -0,0 +1,320 @@
+package tree
+
+import (
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	common "github.com/PlayerR9/mygo-data/common"
+)
+
+// persistentOf returns the S-expression of a persistent tree.
+//
+// Parameters:
+//   - t: The test.
+//   - root: The root of the persistent tree.
+//
+// Returns:
+//   - string: The S-expression of the tree.
+func persistentOf(t *testing.T, root *PersistentNode[string, string]) string {
+	t.Helper()
+
+	str := sexprOf(t, root.ToNode())
+	return str
+}
+
+// TestPersistentEdits checks the result of each edit, that the edited version
+// shares the subtrees the edit did not touch and that the old version is left
+// unchanged.
+func TestPersistentEdits(t *testing.T) {
+	const base = `(R (A (X) (Y)) (B (Z)) (C))`
+
+	leaf := NewPersistentNode[string, string]("N", "")
+
+	tests := []struct {
+		name   string
+		edit   func(p *PersistentNode[string, string]) (*PersistentNode[string, string], error)
+		want   string
+		shared [][]int
+	}{
+		{
+			name: "ReplaceAt",
+			edit: func(p *PersistentNode[string, string]) (*PersistentNode[string, string], error) {
+				return p.ReplaceAt([]int{0, 1}, leaf)
+			},
+			want:   `(R (A (X) (N)) (B (Z)) (C))`,
+			shared: [][]int{{0, 0}, {1}, {2}},
+		},
+		{
+			name: "ReplaceAtRoot",
+			edit: func(p *PersistentNode[string, string]) (*PersistentNode[string, string], error) {
+				return p.ReplaceAt(nil, leaf)
+			},
+			want: `(N)`,
+		},
+		{
+			name: "InsertAt",
+			edit: func(p *PersistentNode[string, string]) (*PersistentNode[string, string], error) {
+				return p.InsertAt([]int{1, 0}, leaf)
+			},
+			want:   `(R (A (X) (Y)) (B (N) (Z)) (C))`,
+			shared: [][]int{{0}, {2}},
+		},
+		{
+			name: "InsertAtEnd",
+			edit: func(p *PersistentNode[string, string]) (*PersistentNode[string, string], error) {
+				return p.InsertAt([]int{3}, leaf)
+			},
+			want:   `(R (A (X) (Y)) (B (Z)) (C) (N))`,
+			shared: [][]int{{0}, {1}, {2}},
+		},
+		{
+			name: "DeleteAt",
+			edit: func(p *PersistentNode[string, string]) (*PersistentNode[string, string], error) {
+				return p.DeleteAt([]int{0, 0})
+			},
+			want:   `(R (A (Y)) (B (Z)) (C))`,
+			shared: [][]int{{1}, {2}},
+		},
+		{
+			name: "DeleteAtLastChild",
+			edit: func(p *PersistentNode[string, string]) (*PersistentNode[string, string], error) {
+				return p.DeleteAt([]int{1, 0})
+			},
+			want:   `(R (A (X) (Y)) (B) (C))`,
+			shared: [][]int{{0}, {2}},
+		},
+	}
+
+	for _, tt := range tests {
+		old := Persist(mustSExpr(t, base))
+
+		got, err := tt.edit(old)
+		if err != nil {
+			t.Fatalf("%s() = %v", tt.name, err)
+		}
+
+		if str := persistentOf(t, got); str != tt.want {
+			t.Errorf("%s() = %s, want %s", tt.name, str, tt.want)
+		}
+
+		if str := persistentOf(t, old); str != base {
+			t.Errorf("%s() changed the old version to %s", tt.name, str)
+		}
+
+		for _, path := range tt.shared {
+			if got.At(path) != old.At(path) {
+				t.Errorf("%s() copied the untouched node at %s", tt.name, pathString(path))
+			}
+		}
+	}
+}
+
+// TestPersistentEditErrors checks that invalid edits are rejected and do not
+// change the tree.
+func TestPersistentEditErrors(t *testing.T) {
+	const base = `(R (A (X)) (B))`
+
+	p := Persist(mustSExpr(t, base))
+	leaf := NewPersistentNode[string, string]("N", "")
+
+	edits := map[string]func() (*PersistentNode[string, string], error){
+		"ReplaceAt(nil node)":     func() (*PersistentNode[string, string], error) { return p.ReplaceAt([]int{0}, nil) },
+		"ReplaceAt(missing)":      func() (*PersistentNode[string, string], error) { return p.ReplaceAt([]int{0, 1}, leaf) },
+		"ReplaceAt(missing path)": func() (*PersistentNode[string, string], error) { return p.ReplaceAt([]int{5, 0}, leaf) },
+		"InsertAt(nil node)":      func() (*PersistentNode[string, string], error) { return p.InsertAt([]int{0}, nil) },
+		"InsertAt(empty path)":    func() (*PersistentNode[string, string], error) { return p.InsertAt(nil, leaf) },
+		"InsertAt(out of range)":  func() (*PersistentNode[string, string], error) { return p.InsertAt([]int{0, 2}, leaf) },
+		"InsertAt(negative)":      func() (*PersistentNode[string, string], error) { return p.InsertAt([]int{-1}, leaf) },
+		"DeleteAt(empty path)":    func() (*PersistentNode[string, string], error) { return p.DeleteAt(nil) },
+		"DeleteAt(missing)":       func() (*PersistentNode[string, string], error) { return p.DeleteAt([]int{1, 0}) },
+	}
+
+	for name, edit := range edits {
+		got, err := edit()
+
+		var bad_param *common.ErrBadParam
+
+		if got != nil || !errors.As(err, &bad_param) {
+			t.Errorf("%s = %v, %v, want nil and a *common.ErrBadParam", name, got, err)
+		}
+	}
+
+	if str := persistentOf(t, p); str != base {
+		t.Fatalf("failed edits changed the tree to %s", str)
+	}
+
+	var nil_node *PersistentNode[string, string]
+
+	if _, err := nil_node.ReplaceAt(nil, leaf); err != common.ErrNilReceiver {
+		t.Errorf("ReplaceAt() on a nil receiver = %v, want %v", err, common.ErrNilReceiver)
+	}
+
+	if _, err := nil_node.InsertAt([]int{0}, leaf); err != common.ErrNilReceiver {
+		t.Errorf("InsertAt() on a nil receiver = %v, want %v", err, common.ErrNilReceiver)
+	}
+
+	if _, err := nil_node.DeleteAt([]int{0}); err != common.ErrNilReceiver {
+		t.Errorf("DeleteAt() on a nil receiver = %v, want %v", err, common.ErrNilReceiver)
+	}
+}
+
+// TestPersistentVersions applies random edits both to a persistent tree and
+// to a mutable copy, and checks at the end that every version kept is still
+// the tree it was when it was created.
+func TestPersistentVersions(t *testing.T) {
+	rng := rand.New(rand.NewPCG(18, 18))
+
+	for range 50 {
+		mutable := randomTree(rng, 1+rng.IntN(30))
+		p := Persist(mutable)
+
+		type version struct {
+			root *PersistentNode[string, string]
+			want string
+		}
+
+		versions := []version{{p, sexprOf(t, mutable)}}
+
+		for range 20 {
+			nodes := slices.Collect(PreOrder(mutable))
+			n := nodes[rng.IntN(len(nodes))]
+			path := pathOf(mutable, n)
+
+			var err error
+
+			switch {
+			case rng.IntN(3) == 0:
+				idx := rng.IntN(countChildren(n) + 1)
+
+				p, err = p.InsertAt(append(path, idx), NewPersistentNode[string, string]("New", "x"))
+
+				_ = insertChildAt(n, NewBaseNode("New", "x"), idx)
+			case n != mutable && rng.IntN(2) == 0:
+				p, err = p.DeleteAt(path)
+
+				_ = n.Detach()
+			default:
+				p, err = p.ReplaceAt(path, p.At(path).WithData(n.Data+"'"))
+
+				n.Data += "'"
+			}
+
+			if err != nil {
+				t.Fatalf("editing %s at %s = %v", versions[len(versions)-1].want, pathString(path), err)
+			}
+
+			versions = append(versions, version{p, sexprOf(t, mutable)})
+		}
+
+		for i, v := range versions {
+			if got := persistentOf(t, v.root); got != v.want {
+				t.Fatalf("version #%d = %s, want %s", i, got, v.want)
+			}
+		}
+	}
+}
+
+// countChildren returns the number of children of the node.
+//
+// Parameters:
+//   - n: The node.
+//
+// Returns:
+//   - int: The number of children.
+func countChildren(n *BaseNode) int {
+	var count int
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		count++
+	}
+
+	return count
+}
+
+// TestPersistRoundTrip checks that Persist and ToNode convert trees, spans
+// included, without loss and without sharing spans.
+func TestPersistRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewPCG(8, 1))
+
+	trees := []*BaseNode{spannedTree()}
+
+	for range 50 {
+		trees = append(trees, randomTree(rng, 1+rng.IntN(40)))
+	}
+
+	for _, root := range trees {
+		p := Persist(root)
+		back := p.ToNode()
+
+		if !Equal(root, back) {
+			t.Fatalf("Persist(%s).ToNode() = %s", sexprOf(t, root), sexprOf(t, back))
+		}
+
+		if err := Validate(back); err != nil {
+			t.Fatalf("ToNode() produced an invalid tree: %v", err)
+		}
+
+		for a, b := range zipPreOrder(root, back) {
+			if (a.Span == nil) != (b.Span == nil) || a.Span != nil && (a.Span == b.Span || *a.Span != *b.Span) {
+				t.Fatalf("the span of %v converted as %v", a, b.Span)
+			}
+		}
+	}
+
+	if Persist[string, string](nil) != nil {
+		t.Fatal("Persist(nil) != nil")
+	}
+
+	var nil_node *PersistentNode[string, string]
+
+	if nil_node.ToNode() != nil {
+		t.Fatal("ToNode() of a nil node != nil")
+	}
+}
+
+// TestPersistentAccessors checks the accessors and that they do not expose the
+// internal state of the node.
+func TestPersistentAccessors(t *testing.T) {
+	x := NewPersistentNode[string, string]("X", "x")
+	p := NewPersistentNode("R", "r", nil, x, NewPersistentNode[string, string]("Y", ""))
+
+	if p.Type() != "R" || p.Data() != "r" || p.Len() != 2 || p.String() != `Node[R ("r")]` {
+		t.Fatalf("the accessors of %v returned wrong values", p)
+	}
+
+	if p.Child(0) != x || p.Child(2) != nil || p.Child(-1) != nil {
+		t.Fatal("Child() returned wrong children")
+	}
+
+	if p.At([]int{0}) != x || p.At(nil) != p || p.At([]int{0, 0}) != nil || p.At([]int{1, 0, 0}) != nil {
+		t.Fatal("At() returned wrong nodes")
+	}
+
+	children := p.Children()
+	children[0] = nil
+
+	if p.Child(0) != x || x.Children() != nil {
+		t.Fatal("Children() exposed the children of the node")
+	}
+
+	span := &Span{Start: Position{Offset: 1}, End: Position{Offset: 2}}
+
+	spanned := x.WithSpan(span)
+	span.End.Offset = 9
+
+	if got := spanned.Span(); got == nil || got.End.Offset != 2 || x.Span() != nil {
+		t.Fatalf("WithSpan() did not copy the span: %v", got)
+	}
+
+	spanned.Span().End.Offset = 9
+
+	if spanned.Span().End.Offset != 2 {
+		t.Fatal("Span() exposed the span of the node")
+	}
+
+	if changed := p.WithData("s"); changed.Data() != "s" || p.Data() != "r" || changed.Child(0) != x {
+		t.Fatal("WithData() changed the receiver or did not share the children")
+	}
+}
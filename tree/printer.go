@@ -0,0 +1,216 @@
+package tree
+
+import (
+	"io"
+	"strconv"
+
+	common "github.com/PlayerR9/mygo-data/common"
+)
+
+const (
+	// DefaultIndent is the indentation used by Printer when no indent is given.
+	DefaultIndent string = "   "
+)
+
+// Printer writes a textual representation of a tree to an io.Writer. The
+// printing is iterative so that it does not overflow the stack on deep trees.
+//
+// The zero value is ready to use and produces the same output as TreeToString.
+type Printer[T interface {
+	Children() []T
+
+	Node
+}] struct {
+	// Indent is the string used to indent each level of the tree. If empty,
+	// DefaultIndent is used. It is ignored when Connectors is true.
+	Indent string
+
+	// Connectors, if true, draws the tree with box-drawing connectors
+	// (├── and └──) instead of plain indentation.
+	Connectors bool
+
+	// MaxDepth is the maximum depth to print, where the root is at depth 0.
+	// Children of nodes at the maximum depth are elided. If zero or negative,
+	// the depth is not limited.
+	MaxDepth int
+
+	// MaxChildren is the maximum number of children printed per node. The
+	// remaining children are elided. If zero or negative, the number of
+	// children is not limited.
+	MaxChildren int
+
+	// Label returns the label of a node. If nil, the node's String method is
+	// used.
+	Label func(node T) string
+
+	// Spans, if true, appends the source span of each node that has one. Only
+	// GenericNode (and so BaseNode) and PersistentNode nodes have spans.
+	Spans bool
+}
+
+// spanner is implemented by nodes that may have a source span.
+type spanner interface {
+	// sourceSpan returns the span of the node.
+	//
+	// Returns:
+	//   - *Span: The span of the node. Nil if the node has none.
+	sourceSpan() *Span
+}
+
+// printFrame is a pending line of the printer.
+type printFrame[T any] struct {
+	// node is the node to print. Ignored if elided is positive.
+	node T
+
+	// depth is the depth of the node.
+	depth int
+
+	// prefix is the prefix inherited from the ancestors.
+	prefix string
+
+	// is_last is true if the line is the last one among its siblings.
+	is_last bool
+
+	// elided is the number of elided nodes. If positive, an elision line is
+	// printed instead of the node.
+	elided int
+}
+
+// errWriter wraps an io.Writer and remembers the first error that occurred.
+type errWriter struct {
+	// w is the underlying writer.
+	w io.Writer
+
+	// err is the first error that occurred.
+	err error
+}
+
+// WriteString writes the string to the underlying writer unless a previous
+// write failed.
+//
+// Parameters:
+//   - str: The string to write.
+func (ew *errWriter) WriteString(str string) {
+	if ew.err != nil {
+		return
+	}
+
+	_, ew.err = io.WriteString(ew.w, str)
+}
+
+// Fprint writes the tree rooted at root to the given writer, one node per line.
+//
+// Parameters:
+//   - w: The writer to write to.
+//   - root: The root of the tree to print.
+//
+// Returns:
+//   - error: An error if the writer is nil or if a write failed.
+//
+// Errors:
+//   - common.ErrBadParam: If the writer is nil.
+//   - any other error: Returned by the writer.
+func (p Printer[T]) Fprint(w io.Writer, root T) error {
+	if w == nil {
+		err := common.NewErrNilParam("w")
+		return err
+	}
+
+	indent := p.Indent
+	if indent == "" {
+		indent = DefaultIndent
+	}
+
+	ew := &errWriter{w: w}
+
+	stack := []printFrame[T]{
+		{node: root, is_last: true},
+	}
+
+	for len(stack) > 0 && ew.err == nil {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		ew.WriteString(top.prefix)
+
+		var child_prefix string
+
+		switch {
+		case !p.Connectors:
+			child_prefix = top.prefix + indent
+		case top.depth == 0:
+			child_prefix = ""
+		case top.is_last:
+			ew.WriteString("└── ")
+			child_prefix = top.prefix + "    "
+		default:
+			ew.WriteString("├── ")
+			child_prefix = top.prefix + "│   "
+		}
+
+		if top.elided > 0 {
+			ew.WriteString("... ")
+			ew.WriteString(strconv.Itoa(top.elided))
+			ew.WriteString(" more\n")
+
+			continue
+		}
+
+		if p.Label == nil {
+			ew.WriteString(top.node.String())
+		} else {
+			ew.WriteString(p.Label(top.node))
+		}
+
+		if p.Spans {
+			if sn, ok := any(top.node).(spanner); ok {
+				if span := sn.sourceSpan(); span != nil {
+					ew.WriteString(" @ ")
+					ew.WriteString(span.String())
+				}
+			}
+		}
+
+		ew.WriteString("\n")
+
+		children := top.node.Children()
+		if len(children) == 0 {
+			continue
+		}
+
+		if p.MaxDepth > 0 && top.depth >= p.MaxDepth {
+			stack = append(stack, printFrame[T]{
+				depth:   top.depth + 1,
+				prefix:  child_prefix,
+				is_last: true,
+				elided:  len(children),
+			})
+
+			continue
+		}
+
+		has_elision := p.MaxChildren > 0 && len(children) > p.MaxChildren
+
+		if has_elision {
+			stack = append(stack, printFrame[T]{
+				depth:   top.depth + 1,
+				prefix:  child_prefix,
+				is_last: true,
+				elided:  len(children) - p.MaxChildren,
+			})
+
+			children = children[:p.MaxChildren]
+		}
+
+		for i := len(children) - 1; i >= 0; i-- {
+			stack = append(stack, printFrame[T]{
+				node:    children[i],
+				depth:   top.depth + 1,
+				prefix:  child_prefix,
+				is_last: i == len(children)-1 && !has_elision,
+			})
+		}
+	}
+
+	return ew.err
+}
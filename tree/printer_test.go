@@ -0,0 +1,137 @@
+package tree
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// errFailingWriter is the error returned by a failingWriter.
+var errFailingWriter = errors.New("write failed")
+
+// failingWriter is a writer that always fails.
+type failingWriter struct{}
+
+// Write implements io.Writer.
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errFailingWriter
+}
+
+// TestPrinter checks the output of the printer against golden outputs.
+func TestPrinter(t *testing.T) {
+	root := mustSExpr(t, `(A (B (D) (E (G))) (C "c" (F)) (H))`)
+
+	tests := []struct {
+		name    string
+		printer Printer[*BaseNode]
+		want    string
+	}{
+		{
+			name:    "Default",
+			printer: Printer[*BaseNode]{},
+			want: `Node[A]
+   Node[B]
+      Node[D]
+      Node[E]
+         Node[G]
+   Node[C ("c")]
+      Node[F]
+   Node[H]
+`,
+		},
+		{
+			name:    "Indent",
+			printer: Printer[*BaseNode]{Indent: "\t", MaxDepth: 1},
+			want: "Node[A]\n" +
+				"\tNode[B]\n" +
+				"\t\t... 2 more\n" +
+				"\tNode[C (\"c\")]\n" +
+				"\t\t... 1 more\n" +
+				"\tNode[H]\n",
+		},
+		{
+			name:    "Connectors",
+			printer: Printer[*BaseNode]{Connectors: true},
+			want: `Node[A]
+├── Node[B]
+│   ├── Node[D]
+│   └── Node[E]
+│       └── Node[G]
+├── Node[C ("c")]
+│   └── Node[F]
+└── Node[H]
+`,
+		},
+		{
+			name:    "MaxChildren",
+			printer: Printer[*BaseNode]{Connectors: true, MaxChildren: 1},
+			want: `Node[A]
+├── Node[B]
+│   ├── Node[D]
+│   └── ... 1 more
+└── ... 2 more
+`,
+		},
+		{
+			name:    "MaxDepth",
+			printer: Printer[*BaseNode]{Connectors: true, MaxDepth: 1},
+			want: `Node[A]
+├── Node[B]
+│   └── ... 2 more
+├── Node[C ("c")]
+│   └── ... 1 more
+└── Node[H]
+`,
+		},
+		{
+			name: "Label",
+			printer: Printer[*BaseNode]{
+				MaxDepth: 2,
+				Label:    func(n *BaseNode) string { return strings.ToLower(n.Type) },
+			},
+			want: `a
+   b
+      d
+      e
+         ... 1 more
+   c
+      f
+   h
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		var builder strings.Builder
+
+		err := tt.printer.Fprint(&builder, root)
+		if err != nil {
+			t.Fatalf("%s: Fprint() = %v", tt.name, err)
+		}
+
+		if got := builder.String(); got != tt.want {
+			t.Errorf("%s: Fprint() =\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+	}
+
+	if got, want := TreeToString(root), tests[0].want; got != want {
+		t.Errorf("TreeToString() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// TestPrinterErrors checks that the printer reports a nil or failing writer.
+func TestPrinterErrors(t *testing.T) {
+	root := mustSExpr(t, `(A (B))`)
+
+	var p Printer[*BaseNode]
+
+	err := p.Fprint(nil, root)
+	if err == nil {
+		t.Fatal("Fprint() on a nil writer succeeded")
+	}
+
+	err = p.Fprint(failingWriter{}, root)
+	if err != errFailingWriter {
+		t.Fatalf("Fprint() = %v, want %v", err, errFailingWriter)
+	}
+}
@@ -0,0 +1,474 @@
+package tree
+
+import (
+	common "github.com/PlayerR9/mygo-data/common"
+)
+
+// Action tells Walk how to proceed after a hook.
+type Action int
+
+const (
+	// Continue proceeds with the walk.
+	Continue Action = iota
+
+	// SkipChildren does not visit the children of the current node. It is
+	// only meaningful when returned by Enter; Leave is still called.
+	SkipChildren
+
+	// Stop ends the walk.
+	Stop
+)
+
+// Visitor is a set of hooks called by Walk.
+type Visitor interface {
+	// Enter is called before the children of the node are visited.
+	//
+	// Parameters:
+	//   - ctx: The context of the walk, positioned on the node.
+	//
+	// Returns:
+	//   - Action: How to proceed.
+	//   - error: An error that stops the walk.
+	Enter(ctx *WalkContext) (Action, error)
+
+	// Leave is called after the children of the node are visited.
+	//
+	// Parameters:
+	//   - ctx: The context of the walk, positioned on the node.
+	//
+	// Returns:
+	//   - Action: How to proceed. SkipChildren is the same as Continue.
+	//   - error: An error that stops the walk.
+	Leave(ctx *WalkContext) (Action, error)
+}
+
+// VisitorFuncs is a Visitor made of functions. Nil functions return Continue.
+type VisitorFuncs struct {
+	// EnterFunc is called by Enter.
+	EnterFunc func(ctx *WalkContext) (Action, error)
+
+	// LeaveFunc is called by Leave.
+	LeaveFunc func(ctx *WalkContext) (Action, error)
+}
+
+// Enter implements Visitor.
+func (v VisitorFuncs) Enter(ctx *WalkContext) (Action, error) {
+	if v.EnterFunc == nil {
+		return Continue, nil
+	}
+
+	act, err := v.EnterFunc(ctx)
+	return act, err
+}
+
+// Leave implements Visitor.
+func (v VisitorFuncs) Leave(ctx *WalkContext) (Action, error) {
+	if v.LeaveFunc == nil {
+		return Continue, nil
+	}
+
+	act, err := v.LeaveFunc(ctx)
+	return act, err
+}
+
+// WalkContext is the state of a walk, passed to the hooks of a Visitor. It
+// allows the hooks to edit the tree around the current node.
+type WalkContext struct {
+	// root is the root of the walked tree.
+	root *BaseNode
+
+	// node is the current node. Nil if it was deleted.
+	node *BaseNode
+
+	// depth is the depth of the current node.
+	depth int
+
+	// next is the node that followed the current node when it was deleted.
+	next *BaseNode
+
+	// parent is the parent of the current node when it was deleted.
+	parent *BaseNode
+}
+
+// Node returns the current node.
+//
+// Returns:
+//   - *BaseNode: The current node. Nil if it was deleted.
+func (ctx *WalkContext) Node() *BaseNode {
+	return ctx.node
+}
+
+// Depth returns the depth of the current node, where the root of the walk is
+// at depth 0.
+//
+// Returns:
+//   - int: The depth of the current node.
+func (ctx *WalkContext) Depth() int {
+	return ctx.depth
+}
+
+// Root returns the root of the walked tree, taking into account replacements
+// and deletions of the root.
+//
+// Returns:
+//   - *BaseNode: The root of the walked tree. Nil if it was deleted.
+func (ctx *WalkContext) Root() *BaseNode {
+	return ctx.root
+}
+
+// Replace replaces the current node with the given node, which becomes the
+// current node. If called from Enter, the children of the new node are
+// visited instead of the old ones. The old node keeps its children, so the
+// new node may be one of them.
+//
+// Parameters:
+//   - node: The node that replaces the current node.
+//
+// Returns:
+//   - error: An error if the node could not be replaced.
+//
+// Errors:
+//   - common.ErrBadParam: If the node is nil or an ancestor of the current node.
+//   - ErrNodeDeleted: If the current node was deleted.
+func (ctx *WalkContext) Replace(node *BaseNode) error {
+	if node == nil {
+		err := common.NewErrNilParam("node")
+		return err
+	} else if ctx.node == nil {
+		return ErrNodeDeleted
+	}
+
+	if node == ctx.node {
+		return nil
+	}
+
+	if ctx.node.Parent != nil {
+		err := ctx.node.ReplaceWith(node)
+		if err != nil {
+			return err
+		}
+	} else {
+		_ = node.Detach()
+	}
+
+	if ctx.node == ctx.root {
+		ctx.root = node
+	}
+
+	ctx.node = node
+
+	return nil
+}
+
+// Delete removes the current node, with its subtree, from the tree. Its
+// children are not visited and Leave is not called for it.
+//
+// Returns:
+//   - error: An error if the current node was already deleted.
+//
+// Errors:
+//   - ErrNodeDeleted: If the current node was deleted.
+func (ctx *WalkContext) Delete() error {
+	if ctx.node == nil {
+		return ErrNodeDeleted
+	}
+
+	ctx.next = ctx.node.NextSibling
+	ctx.parent = ctx.node.Parent
+
+	_ = ctx.node.Detach()
+
+	if ctx.node == ctx.root {
+		ctx.root = nil
+	}
+
+	ctx.node = nil
+
+	return nil
+}
+
+// InsertBefore inserts the given node as the previous sibling of the current
+// node. The inserted node is not visited.
+//
+// Parameters:
+//   - node: The node to insert.
+//
+// Returns:
+//   - error: An error if the node could not be inserted.
+//
+// Errors:
+//   - ErrNodeDeleted: If the current node was deleted.
+//   - ErrNoParent: If the current node is the root of the walk.
+//   - common.ErrBadParam: If the node is the current node or one of its
+//     ancestors.
+func (ctx *WalkContext) InsertBefore(node *BaseNode) error {
+	if ctx.node == nil {
+		return ErrNodeDeleted
+	} else if ctx.depth == 0 {
+		return ErrNoParent
+	}
+
+	err := ctx.node.InsertBefore(node)
+	return err
+}
+
+// InsertAfter inserts the given node as the next sibling of the current node.
+// The inserted node is visited after the current node.
+//
+// Parameters:
+//   - node: The node to insert.
+//
+// Returns:
+//   - error: An error if the node could not be inserted.
+//
+// Errors:
+//   - ErrNodeDeleted: If the current node was deleted.
+//   - ErrNoParent: If the current node is the root of the walk.
+//   - common.ErrBadParam: If the node is the current node or one of its
+//     ancestors.
+func (ctx *WalkContext) InsertAfter(node *BaseNode) error {
+	if ctx.node == nil {
+		return ErrNodeDeleted
+	} else if ctx.depth == 0 {
+		return ErrNoParent
+	}
+
+	err := ctx.node.InsertAfter(node)
+	return err
+}
+
+// Walk walks the tree rooted at root in depth-first order, calling Enter
+// before and Leave after the children of each node. The hooks may edit the
+// tree through the WalkContext. The walk follows the links of the nodes and is
+// not recursive.
+//
+// Parameters:
+//   - root: The root of the tree to walk.
+//   - v: The visitor.
+//
+// Returns:
+//   - *BaseNode: The root of the tree after the walk, which differs from the
+//     given root if it was replaced. Nil if it was deleted.
+//   - error: An error if a parameter is nil or if a hook returned an error.
+//
+// Errors:
+//   - common.ErrBadParam: If the visitor is nil.
+//   - any other error: Returned by the hooks.
+func Walk(root *BaseNode, v Visitor) (*BaseNode, error) {
+	if v == nil {
+		err := common.NewErrNilParam("v")
+		return root, err
+	} else if root == nil {
+		return nil, nil
+	}
+
+	ctx := &WalkContext{
+		root: root,
+	}
+
+	node := root
+	entering := true
+
+	for {
+		ctx.node = node
+		ctx.next = nil
+		ctx.parent = nil
+
+		if entering {
+			act, err := v.Enter(ctx)
+			if err != nil {
+				return ctx.root, err
+			} else if act == Stop {
+				return ctx.root, nil
+			}
+
+			if ctx.node != nil {
+				node = ctx.node
+
+				if act != SkipChildren && node.FirstChild != nil {
+					node = node.FirstChild
+					ctx.depth++
+
+					continue
+				}
+
+				ctx.node = node
+
+				act, err = v.Leave(ctx)
+				if err != nil {
+					return ctx.root, err
+				} else if act == Stop {
+					return ctx.root, nil
+				}
+			}
+		} else {
+			act, err := v.Leave(ctx)
+			if err != nil {
+				return ctx.root, err
+			} else if act == Stop {
+				return ctx.root, nil
+			}
+		}
+
+		if ctx.depth == 0 {
+			return ctx.root, nil
+		}
+
+		var next, parent *BaseNode
+
+		if ctx.node == nil {
+			next, parent = ctx.next, ctx.parent
+		} else {
+			next, parent = ctx.node.NextSibling, ctx.node.Parent
+		}
+
+		if next != nil {
+			node = next
+			entering = true
+		} else {
+			node = parent
+			entering = false
+			ctx.depth--
+		}
+	}
+}
+
+const (
+	// DefaultMaxPasses is the maximum number of passes of a Rewriter whose
+	// MaxPasses is not positive.
+	DefaultMaxPasses int = 100
+)
+
+// Rule is a named rewrite rule.
+type Rule struct {
+	// Name is the name of the rule, used in the trace.
+	Name string
+
+	// Apply tries to rewrite the node.
+	//
+	// Parameters:
+	//   - node: The node to rewrite. Its children were already rewritten
+	//     during the current pass.
+	//
+	// Returns:
+	//   - *BaseNode: The node that replaces the given one. It may be the node
+	//     itself if it was modified in place, and nil to delete it.
+	//   - bool: True if the rule applied, false otherwise. If false, the
+	//     returned node is ignored.
+	Apply func(node *BaseNode) (*BaseNode, bool)
+}
+
+// RuleApplication is an entry of the trace of a Rewriter.
+type RuleApplication struct {
+	// Rule is the name of the rule that applied.
+	Rule string
+
+	// Pass is the pass during which the rule applied, starting from 1.
+	Pass int
+
+	// Path is the path of child indexes of the node, from the root as it was
+	// when the rule applied.
+	Path []int
+
+	// Before is the string representation of the node before the rule applied.
+	Before string
+}
+
+// Rewriter applies rewrite rules bottom-up until none applies.
+type Rewriter struct {
+	// Rules are the rules to apply. At each node, the first rule that applies
+	// is used.
+	Rules []Rule
+
+	// MaxPasses is the maximum number of passes over the tree. If zero or
+	// negative, DefaultMaxPasses is used.
+	MaxPasses int
+}
+
+// Rewrite rewrites the tree rooted at root in place. Each pass walks the tree
+// bottom-up and applies at each node the first rule that applies; passes are
+// repeated until a pass applies no rule (a fixed point) or the maximum number
+// of passes is reached.
+//
+// Parameters:
+//   - root: The root of the tree to rewrite.
+//
+// Returns:
+//   - *BaseNode: The root of the rewritten tree. Nil if it was deleted.
+//   - []RuleApplication: The trace of the rules that applied, in order.
+//   - error: An error if a replacement failed or if no fixed point was reached.
+//
+// Errors:
+//   - ErrNoFixpoint: If rules still applied during the last allowed pass.
+//   - common.ErrBadParam: If a rule returned an ancestor of the node.
+func (r Rewriter) Rewrite(root *BaseNode) (*BaseNode, []RuleApplication, error) {
+	max_passes := r.MaxPasses
+	if max_passes <= 0 {
+		max_passes = DefaultMaxPasses
+	}
+
+	var trace []RuleApplication
+
+	for pass := 1; pass <= max_passes; pass++ {
+		var changed bool
+
+		v := VisitorFuncs{
+			LeaveFunc: func(ctx *WalkContext) (Action, error) {
+				node := ctx.Node()
+				walk_root := ctx.Root()
+
+				// A rule may modify or move the node and its siblings, so what
+				// the trace needs is captured before any rule runs; the string
+				// is only computed if a rule applies.
+				type_, data := node.Type, node.Data
+				path := pathOf(walk_root, node)
+
+				for _, rule := range r.Rules {
+					if rule.Apply == nil {
+						continue
+					}
+
+					repl, ok := rule.Apply(node)
+					if !ok {
+						continue
+					}
+
+					changed = true
+
+					trace = append(trace, RuleApplication{
+						Rule:   rule.Name,
+						Pass:   pass,
+						Path:   path,
+						Before: nodeString(type_, data),
+					})
+
+					if repl == nil {
+						_ = ctx.Delete()
+					} else {
+						err := ctx.Replace(repl)
+						if err != nil {
+							return Stop, err
+						}
+					}
+
+					break
+				}
+
+				return Continue, nil
+			},
+		}
+
+		new_root, err := Walk(root, v)
+		if err != nil {
+			return new_root, trace, err
+		}
+
+		root = new_root
+
+		if !changed || root == nil {
+			return root, trace, nil
+		}
+	}
+
+	return root, trace, ErrNoFixpoint
+}
@@ -0,0 +1,235 @@
+package tree
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+)
+
+// mustSExpr decodes the S-expression or fails the test.
+//
+// Parameters:
+//   - t: The test.
+//   - input: The S-expression of the tree.
+//
+// Returns:
+//   - *BaseNode: The root of the decoded tree. Never returns nil.
+func mustSExpr(t *testing.T, input string) *BaseNode {
+	t.Helper()
+
+	root, err := UnmarshalSExpr(input)
+	if err != nil {
+		t.Fatalf("UnmarshalSExpr(%q) returned %v", input, err)
+	}
+
+	return root
+}
+
+// sexprOf encodes the tree or fails the test.
+//
+// Parameters:
+//   - t: The test.
+//   - root: The root of the tree.
+//
+// Returns:
+//   - string: The S-expression of the tree.
+func sexprOf(t *testing.T, root *BaseNode) string {
+	t.Helper()
+
+	str, err := MarshalSExpr(root)
+	if err != nil {
+		t.Fatalf("MarshalSExpr returned %v", err)
+	}
+
+	return str
+}
+
+// foldRule folds Add and Mul nodes whose children are all numbers.
+var foldRule = Rule{
+	Name: "fold",
+	Apply: func(node *BaseNode) (*BaseNode, bool) {
+		if node.Type != "Add" && node.Type != "Mul" || node.FirstChild == nil {
+			return nil, false
+		}
+
+		acc := 0
+		if node.Type == "Mul" {
+			acc = 1
+		}
+
+		for c := node.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != "Num" {
+				return nil, false
+			}
+
+			v, _ := strconv.Atoi(c.Data)
+
+			if node.Type == "Add" {
+				acc += v
+			} else {
+				acc *= v
+			}
+		}
+
+		return NewBaseNode("Num", strconv.Itoa(acc)), true
+	},
+}
+
+// dropNopRule deletes Nop nodes.
+var dropNopRule = Rule{
+	Name: "drop-nop",
+	Apply: func(node *BaseNode) (*BaseNode, bool) {
+		return nil, node.Type == "Nop"
+	},
+}
+
+// TestRewriteFold checks that rules are applied bottom-up until a fixed point
+// and that the trace records where and on what they applied.
+func TestRewriteFold(t *testing.T) {
+	root := mustSExpr(t, `(Mul (Add (Num "1") (Num "2")) (Num "4"))`)
+
+	r := Rewriter{Rules: []Rule{foldRule}}
+
+	got, trace, err := r.Rewrite(root)
+	if err != nil {
+		t.Fatalf("Rewrite returned %v", err)
+	}
+
+	if str := sexprOf(t, got); str != `(Num "12")` {
+		t.Fatalf("Rewrite produced %s", str)
+	}
+
+	want := []RuleApplication{
+		{Rule: "fold", Pass: 1, Path: []int{0}, Before: "Node[Add]"},
+		{Rule: "fold", Pass: 1, Path: nil, Before: "Node[Mul]"},
+	}
+
+	if len(trace) != len(want) {
+		t.Fatalf("trace = %v, want %v", trace, want)
+	}
+
+	for i := range want {
+		if trace[i].Rule != want[i].Rule || trace[i].Pass != want[i].Pass ||
+			!slices.Equal(trace[i].Path, want[i].Path) || trace[i].Before != want[i].Before {
+			t.Fatalf("trace[%d] = %+v, want %+v", i, trace[i], want[i])
+		}
+	}
+}
+
+// TestRewriteDelete checks that a rule can delete nodes, that the first rule
+// that applies wins and that the trace paths are those of the tree as it was
+// when each rule applied.
+func TestRewriteDelete(t *testing.T) {
+	root := mustSExpr(t, `(Block (Nop) (Add (Num "1") (Nop) (Num "2")) (Nop))`)
+
+	r := Rewriter{Rules: []Rule{dropNopRule, foldRule}}
+
+	got, trace, err := r.Rewrite(root)
+	if err != nil {
+		t.Fatalf("Rewrite returned %v", err)
+	}
+
+	if str := sexprOf(t, got); str != `(Block (Num "3"))` {
+		t.Fatalf("Rewrite produced %s", str)
+	}
+
+	err = Validate(got)
+	if err != nil {
+		t.Fatalf("Rewrite produced an invalid tree: %v", err)
+	}
+
+	var paths []string
+
+	for _, app := range trace {
+		paths = append(paths, app.Rule+"@"+pathString(app.Path))
+	}
+
+	want := []string{"drop-nop@/0", "drop-nop@/0/1", "fold@/0", "drop-nop@/1"}
+
+	if !slices.Equal(paths, want) {
+		t.Fatalf("trace = %v, want %v", paths, want)
+	}
+}
+
+// TestRewriteNoFixpoint checks that rules that always apply stop after the
+// maximum number of passes.
+func TestRewriteNoFixpoint(t *testing.T) {
+	flip := Rule{
+		Name: "flip",
+		Apply: func(node *BaseNode) (*BaseNode, bool) {
+			if node.Data == "on" {
+				node.Data = "off"
+			} else {
+				node.Data = "on"
+			}
+
+			return node, true
+		},
+	}
+
+	r := Rewriter{Rules: []Rule{flip}, MaxPasses: 3}
+
+	_, trace, err := r.Rewrite(mustSExpr(t, `(Switch)`))
+	if err != ErrNoFixpoint {
+		t.Fatalf("Rewrite returned %v, want ErrNoFixpoint", err)
+	}
+
+	if len(trace) != 3 {
+		t.Fatalf("trace has %d entries, want 3", len(trace))
+	}
+
+	if trace[1].Before != `Node[Switch ("on")]` {
+		t.Fatalf("trace[1].Before = %s", trace[1].Before)
+	}
+}
+
+// TestRewriteDeleteRoot checks that deleting the root ends the rewrite.
+func TestRewriteDeleteRoot(t *testing.T) {
+	r := Rewriter{Rules: []Rule{dropNopRule}}
+
+	got, trace, err := r.Rewrite(mustSExpr(t, `(Nop (Nop))`))
+	if err != nil {
+		t.Fatalf("Rewrite returned %v", err)
+	}
+
+	if got != nil {
+		t.Fatalf("Rewrite returned the root %v, want nil", got)
+	}
+
+	if len(trace) != 2 || trace[1].Path != nil {
+		t.Fatalf("trace = %v", trace)
+	}
+}
+
+// TestRewriteSiblingChanges checks that the trace path of a rule that changes
+// the siblings of its node is that of the node before the rule applied.
+func TestRewriteSiblingChanges(t *testing.T) {
+	shift := Rule{
+		Name: "shift",
+		Apply: func(node *BaseNode) (*BaseNode, bool) {
+			if node.Type != "Dup" {
+				return nil, false
+			}
+
+			_ = node.Parent.FirstChild.Detach()
+			node.Type = "Done"
+
+			return node, true
+		},
+	}
+
+	r := Rewriter{Rules: []Rule{shift}}
+
+	got, trace, err := r.Rewrite(mustSExpr(t, `(Block (X) (A) (Dup) (B))`))
+	if err != nil {
+		t.Fatalf("Rewrite returned %v", err)
+	}
+
+	if str := sexprOf(t, got); str != `(Block (A) (Done) (B))` {
+		t.Fatalf("Rewrite produced %s", str)
+	}
+
+	if len(trace) != 1 || !slices.Equal(trace[0].Path, []int{2}) {
+		t.Fatalf("trace = %v, want a single application at /2", trace)
+	}
+}
@@ -0,0 +1,567 @@
+package tree
+
+import (
+	"iter"
+	"strconv"
+	"strings"
+)
+
+// combinator is the relation between two compound selectors.
+type combinator int
+
+const (
+	// combDescendant matches when the left side is an ancestor (" ").
+	combDescendant combinator = iota
+
+	// combChild matches when the left side is the parent (">").
+	combChild
+
+	// combAdjacent matches when the left side is the previous sibling ("+").
+	combAdjacent
+
+	// combSibling matches when the left side is any previous sibling ("~").
+	combSibling
+)
+
+// attrOp is the comparison operator of an attribute filter.
+type attrOp int
+
+const (
+	// opEqual matches equal values ("=").
+	opEqual attrOp = iota
+
+	// opNotEqual matches different values ("!=").
+	opNotEqual
+
+	// opPrefix matches values that start with the operand ("^=").
+	opPrefix
+
+	// opSuffix matches values that end with the operand ("$=").
+	opSuffix
+
+	// opContains matches values that contain the operand ("*=").
+	opContains
+)
+
+// attrFilter is a filter on the type or the data of a node.
+type attrFilter struct {
+	// is_type is true if the filter is on the type, false if it is on the data.
+	is_type bool
+
+	// op is the comparison operator.
+	op attrOp
+
+	// value is the operand of the comparison.
+	value string
+}
+
+// match checks whether a node with the given type and data satisfies the
+// filter.
+//
+// Parameters:
+//   - type_: The type of the node.
+//   - data: The data of the node.
+//
+// Returns:
+//   - bool: True if the node satisfies the filter, false otherwise.
+func (f attrFilter) match(type_, data string) bool {
+	var str string
+
+	if f.is_type {
+		str = type_
+	} else {
+		str = data
+	}
+
+	switch f.op {
+	case opEqual:
+		return str == f.value
+	case opNotEqual:
+		return str != f.value
+	case opPrefix:
+		return strings.HasPrefix(str, f.value)
+	case opSuffix:
+		return strings.HasSuffix(str, f.value)
+	default:
+		return strings.Contains(str, f.value)
+	}
+}
+
+// compound is a compound selector: a type, attribute filters and positional
+// filters that must all match the same node.
+type compound struct {
+	// type_ is the type to match. Ignored if any_type is true.
+	type_ string
+
+	// any_type is true if any type matches.
+	any_type bool
+
+	// attrs are the attribute filters.
+	attrs []attrFilter
+
+	// first is true if the node must be the first among its siblings.
+	first bool
+
+	// last is true if the node must be the last among its siblings.
+	last bool
+
+	// nth is the 1-based position the node must have among its siblings. Zero
+	// if there is no such constraint.
+	nth int
+}
+
+// match checks whether the node matches the compound selector.
+//
+// Parameters:
+//   - node: The node to check.
+//
+// Returns:
+//   - bool: True if the node matches, false otherwise.
+func (c compound) match(node *BaseNode) bool {
+	if !c.any_type && node.Type != c.type_ {
+		return false
+	}
+
+	for _, attr := range c.attrs {
+		if !attr.match(node.Type, node.Data) {
+			return false
+		}
+	}
+
+	if c.first && node.PrevSibling != nil {
+		return false
+	}
+
+	if c.last && node.NextSibling != nil {
+		return false
+	}
+
+	if c.nth > 0 {
+		pos := 1
+
+		for s := node.PrevSibling; s != nil && pos <= c.nth; s = s.PrevSibling {
+			pos++
+		}
+
+		if pos != c.nth {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Selector is a compiled query over BaseNode trees. See CompileSelector for
+// the syntax.
+type Selector struct {
+	// src is the source of the selector.
+	src string
+
+	// compounds are the compound selectors, from left to right.
+	compounds []compound
+
+	// combs are the combinators; combs[i] relates compounds[i] and
+	// compounds[i+1].
+	combs []combinator
+}
+
+// String implements fmt.Stringer.
+func (s Selector) String() string {
+	return s.src
+}
+
+// CompileSelector compiles a selector. The syntax is close to CSS:
+//
+//	Function > Param[data="x"]:first
+//
+// Where:
+//   - A compound selector is a node type (or '*' for any type) followed by any
+//     number of filters. The type may be omitted if a filter is given. Types
+//     that are not made of letters, digits, '_', '-' and '.' must be quoted.
+//   - [data<op>"value"] and [type<op>"value"] filter on the data or the type,
+//     where <op> is one of "=", "!=", "^=" (prefix), "$=" (suffix) and "*="
+//     (contains).
+//   - :first, :last and :nth(n) filter on the position of the node among its
+//     siblings; n is 1-based.
+//   - Compound selectors are joined by combinators: whitespace (descendant),
+//     '>' (child), '+' (next sibling) and '~' (following sibling).
+//
+// Parameters:
+//   - src: The source of the selector.
+//
+// Returns:
+//   - *Selector: The compiled selector. Nil if an error occurred.
+//   - error: An error if the selector is not valid.
+//
+// Errors:
+//   - *ErrSyntax: If the selector is not valid.
+func CompileSelector(src string) (*Selector, error) {
+	p := &textParser{format: "selector", input: src}
+
+	s := &Selector{
+		src: src,
+	}
+
+	p.skipSpace()
+
+	for {
+		c, err := p.parseCompound()
+		if err != nil {
+			return nil, err
+		}
+
+		s.compounds = append(s.compounds, c)
+
+		had_space := p.skipSpace()
+
+		if p.pos >= len(p.input) {
+			break
+		}
+
+		switch p.input[p.pos] {
+		case '>':
+			s.combs = append(s.combs, combChild)
+		case '+':
+			s.combs = append(s.combs, combAdjacent)
+		case '~':
+			s.combs = append(s.combs, combSibling)
+		default:
+			if !had_space {
+				err := p.errorf("unexpected character " + strconv.QuoteRune(p.peek()))
+				return nil, err
+			}
+
+			s.combs = append(s.combs, combDescendant)
+
+			continue
+		}
+
+		p.pos++
+
+		p.skipSpace()
+	}
+
+	return s, nil
+}
+
+// Match checks whether the node matches the selector. Ancestors and siblings
+// are looked up in the whole tree the node belongs to.
+//
+// Parameters:
+//   - node: The node to check.
+//
+// Returns:
+//   - bool: True if the node matches, false otherwise. False if the receiver or
+//     the node is nil.
+func (s *Selector) Match(node *BaseNode) bool {
+	if s == nil || node == nil {
+		return false
+	}
+
+	ok := s.matchAt(node, len(s.compounds)-1, nil)
+	return ok
+}
+
+// Select returns an iterator over the nodes of the tree rooted at root that
+// match the selector, in pre-order. Only the root and its descendants are
+// considered, both as candidates and when resolving combinators.
+//
+// Parameters:
+//   - root: The root of the tree to query.
+//
+// Returns:
+//   - iter.Seq[*BaseNode]: An iterator over the matching nodes. Never returns nil.
+func (s *Selector) Select(root *BaseNode) iter.Seq[*BaseNode] {
+	if s == nil || root == nil {
+		return func(yield func(*BaseNode) bool) {}
+	}
+
+	fn := func(yield func(*BaseNode) bool) {
+		for node := range PreOrder(root) {
+			if s.matchAt(node, len(s.compounds)-1, root) && !yield(node) {
+				return
+			}
+		}
+	}
+
+	return fn
+}
+
+// matchAt checks whether the node matches the selector made of the first i+1
+// compound selectors. The recursion is bounded by the number of compound
+// selectors, not by the depth of the tree.
+//
+// Parameters:
+//   - node: The node to check.
+//   - i: The index of the compound selector the node must match.
+//   - scope: The root of the tree considered. If nil, the whole tree is
+//     considered.
+//
+// Returns:
+//   - bool: True if the node matches, false otherwise.
+func (s *Selector) matchAt(node *BaseNode, i int, scope *BaseNode) bool {
+	if !s.compounds[i].match(node) {
+		return false
+	}
+
+	if i == 0 {
+		return true
+	}
+
+	var parent, prev *BaseNode
+
+	if node != scope {
+		parent = node.Parent
+		prev = node.PrevSibling
+	}
+
+	switch s.combs[i-1] {
+	case combChild:
+		return parent != nil && s.matchAt(parent, i-1, scope)
+	case combAdjacent:
+		return prev != nil && s.matchAt(prev, i-1, scope)
+	case combSibling:
+		for ; prev != nil; prev = prev.PrevSibling {
+			if s.matchAt(prev, i-1, scope) {
+				return true
+			}
+		}
+	default:
+		for ; parent != nil; parent = parent.Parent {
+			if s.matchAt(parent, i-1, scope) {
+				return true
+			}
+
+			if parent == scope {
+				break
+			}
+		}
+	}
+
+	return false
+}
+
+// parseCompound parses a compound selector at the current position.
+//
+// Returns:
+//   - compound: The compound selector.
+//   - error: An error if the compound selector is not valid.
+//
+// Errors:
+//   - *ErrSyntax: If the compound selector is not valid.
+func (p *textParser) parseCompound() (compound, error) {
+	var c compound
+
+	var has_type bool
+
+	switch r := p.peek(); {
+	case r == '*':
+		p.pos++
+
+		c.any_type = true
+		has_type = true
+	case r == '"':
+		str, err := p.readString()
+		if err != nil {
+			return c, err
+		}
+
+		c.type_ = str
+		has_type = true
+	case isIdentRune(r):
+		c.type_ = p.readIdent()
+		has_type = true
+	default:
+		c.any_type = true
+	}
+
+	var has_filter bool
+
+	for p.pos < len(p.input) {
+		switch p.input[p.pos] {
+		case '[':
+			attr, err := p.parseAttr()
+			if err != nil {
+				return c, err
+			}
+
+			c.attrs = append(c.attrs, attr)
+		case ':':
+			err := p.parsePseudo(&c)
+			if err != nil {
+				return c, err
+			}
+		default:
+			if !has_type && !has_filter {
+				err := p.errorf("expected a node type, '*', '[' or ':'")
+				return c, err
+			}
+
+			return c, nil
+		}
+
+		has_filter = true
+	}
+
+	if !has_type && !has_filter {
+		err := p.errorf("expected a node type, '*', '[' or ':'")
+		return c, err
+	}
+
+	return c, nil
+}
+
+// parseAttr parses an attribute filter at the current position, which must be
+// on a '['.
+//
+// Returns:
+//   - attrFilter: The attribute filter.
+//   - error: An error if the attribute filter is not valid.
+//
+// Errors:
+//   - *ErrSyntax: If the attribute filter is not valid.
+func (p *textParser) parseAttr() (attrFilter, error) {
+	var attr attrFilter
+
+	p.pos++ // Skip '['.
+
+	p.skipSpace()
+
+	name_pos := p.pos
+
+	switch p.readIdent() {
+	case "type":
+		attr.is_type = true
+	case "data":
+		attr.is_type = false
+	default:
+		p.pos = name_pos
+
+		err := p.errorf("expected \"type\" or \"data\"")
+		return attr, err
+	}
+
+	p.skipSpace()
+
+	ops := []struct {
+		str string
+		op  attrOp
+	}{
+		{"=", opEqual},
+		{"!=", opNotEqual},
+		{"^=", opPrefix},
+		{"$=", opSuffix},
+		{"*=", opContains},
+	}
+
+	var found bool
+
+	for _, op := range ops {
+		if strings.HasPrefix(p.input[p.pos:], op.str) {
+			p.pos += len(op.str)
+
+			attr.op = op.op
+			found = true
+
+			break
+		}
+	}
+
+	if !found {
+		err := p.errorf("expected one of \"=\", \"!=\", \"^=\", \"$=\" or \"*=\"")
+		return attr, err
+	}
+
+	p.skipSpace()
+
+	if p.peek() == '"' {
+		str, err := p.readString()
+		if err != nil {
+			return attr, err
+		}
+
+		attr.value = str
+	} else {
+		attr.value = p.readIdent()
+		if attr.value == "" {
+			err := p.errorf("expected a value")
+			return attr, err
+		}
+	}
+
+	p.skipSpace()
+
+	if p.pos >= len(p.input) || p.input[p.pos] != ']' {
+		err := p.errorf("expected ']'")
+		return attr, err
+	}
+
+	p.pos++
+
+	return attr, nil
+}
+
+// parsePseudo parses a positional filter at the current position, which must
+// be on a ':', and adds it to the compound selector.
+//
+// Parameters:
+//   - c: The compound selector to add the filter to.
+//
+// Returns:
+//   - error: An error if the positional filter is not valid.
+//
+// Errors:
+//   - *ErrSyntax: If the positional filter is not valid.
+func (p *textParser) parsePseudo(c *compound) error {
+	p.pos++ // Skip ':'.
+
+	name_pos := p.pos
+
+	switch p.readIdent() {
+	case "first":
+		c.first = true
+	case "last":
+		c.last = true
+	case "nth":
+		if p.pos >= len(p.input) || p.input[p.pos] != '(' {
+			err := p.errorf("expected '(' after :nth")
+			return err
+		}
+
+		p.pos++
+
+		p.skipSpace()
+
+		num_pos := p.pos
+
+		for p.pos < len(p.input) && p.input[p.pos] >= '0' && p.input[p.pos] <= '9' {
+			p.pos++
+		}
+
+		n, err := strconv.Atoi(p.input[num_pos:p.pos])
+		if err != nil || n < 1 {
+			p.pos = num_pos
+
+			err := p.errorf("expected a positive integer")
+			return err
+		}
+
+		p.skipSpace()
+
+		if p.pos >= len(p.input) || p.input[p.pos] != ')' {
+			err := p.errorf("expected ')'")
+			return err
+		}
+
+		p.pos++
+
+		c.nth = n
+	default:
+		p.pos = name_pos
+
+		err := p.errorf("expected \"first\", \"last\" or \"nth\"")
+		return err
+	}
+
+	return nil
+}
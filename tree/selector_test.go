@@ -0,0 +1,133 @@
+package tree
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// selectorTree is the tree queried by the selector tests.
+const selectorTree = `(Prog
+	(Func "main" (Param "a") (Param "b") (Body (Call "print") (Call "exit")))
+	(Func "helper" (Param "x"))
+	(Var "count"))`
+
+// TestSelect checks every combinator and filter against a small program.
+func TestSelect(t *testing.T) {
+	root := mustSExpr(t, selectorTree)
+
+	tests := []struct {
+		selector string
+		want     []string
+	}{
+		{`Param`, []string{"a", "b", "x"}},
+		{`*`, []string{"", "main", "a", "b", "", "print", "exit", "helper", "x", "count"}},
+		{`Prog Call`, []string{"print", "exit"}},
+		{`Prog > Call`, nil},
+		{`Func > Param`, []string{"a", "b", "x"}},
+		{`Func>Body>Call`, []string{"print", "exit"}},
+		{`Param + Param`, []string{"b"}},
+		{`Param + Body`, []string{""}},
+		{`Func ~ Var`, []string{"count"}},
+		{`Func ~ Func`, []string{"helper"}},
+		{`Var ~ Func`, nil},
+		{`Param:first`, []string{"a", "x"}},
+		{`Param:last`, []string{"x"}},
+		{`Func > :last`, []string{"", "x"}},
+		{`Func > :nth(2)`, []string{"b"}},
+		{`:nth(3)`, []string{"", "count"}},
+		{`[data="main"]`, []string{"main"}},
+		{`Call[data!="exit"]`, []string{"print"}},
+		{`[data^="he"]`, []string{"helper"}},
+		{`[data$="t"]`, []string{"print", "exit", "count"}},
+		{`[data*="in"]`, []string{"main", "print"}},
+		{`[type="Var"]`, []string{"count"}},
+		{`[ type ^= Pa ][data=b]`, []string{"b"}},
+		{`Func[data=main] Call:first`, []string{"print"}},
+		{`Func[data=helper] Call`, nil},
+		{`"Param":first`, []string{"a", "x"}},
+	}
+
+	for _, tt := range tests {
+		sel, err := CompileSelector(tt.selector)
+		if err != nil {
+			t.Errorf("CompileSelector(%q) returned %v", tt.selector, err)
+			continue
+		}
+
+		var got []string
+
+		for n := range sel.Select(root) {
+			got = append(got, n.Data)
+		}
+
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s selected %q, want %q", tt.selector, got, tt.want)
+		}
+
+		for n := range PreOrder(root) {
+			if sel.Match(n) != slices.Contains(got, n.Data) && n.Data != "" {
+				t.Errorf("%s: Match(%v) disagrees with Select", tt.selector, n)
+			}
+		}
+	}
+}
+
+// TestSelectScope checks that Select only considers the subtree it is given,
+// while Match looks at the whole tree.
+func TestSelectScope(t *testing.T) {
+	root := mustSExpr(t, selectorTree)
+	body := root.FirstChild.LastChild
+
+	sel, _ := CompileSelector(`Func Call`)
+
+	var got []string
+
+	for n := range sel.Select(body) {
+		got = append(got, n.Data)
+	}
+
+	if got != nil {
+		t.Fatalf("Select() in the body = %q, want none", got)
+	}
+
+	if !sel.Match(body.FirstChild) {
+		t.Fatalf("Match() does not see the ancestors of %v", body.FirstChild)
+	}
+}
+
+// TestCompileSelectorErrors checks the messages and offsets of the syntax
+// errors.
+func TestCompileSelectorErrors(t *testing.T) {
+	tests := []struct {
+		selector string
+		want     string
+	}{
+		{``, `selector input at offset 0: unexpected end of selector; expected a node type, '*', '[' or ':'`},
+		{`Func >`, `selector input at offset 6: unexpected end of selector; expected a node type, '*', '[' or ':'`},
+		{`Func > > Param`, `selector input at offset 7: expected a node type, '*', '[' or ':'`},
+		{`Func)`, `selector input at offset 4: unexpected character ')'`},
+		{`[name="x"]`, `selector input at offset 1: expected "type" or "data"`},
+		{`[data]`, `selector input at offset 5: expected one of "=", "!=", "^=", "$=" or "*="`},
+		{`[data=]`, `selector input at offset 6: expected a value`},
+		{`[data="x"`, `selector input at offset 9: unexpected end of selector; expected ']'`},
+		{`[data="x]`, `selector input at offset 6: unterminated string`},
+		{`:second`, `selector input at offset 1: expected "first", "last" or "nth"`},
+		{`:nth`, `selector input at offset 4: unexpected end of selector; expected '(' after :nth`},
+		{`:nth(0)`, `selector input at offset 5: expected a positive integer`},
+		{`:nth(x)`, `selector input at offset 5: expected a positive integer`},
+		{`:nth(2`, `selector input at offset 6: unexpected end of selector; expected ')'`},
+	}
+
+	for _, tt := range tests {
+		_, err := CompileSelector(tt.selector)
+
+		var syntax_err *ErrSyntax
+
+		if !errors.As(err, &syntax_err) {
+			t.Errorf("CompileSelector(%q) = %v, want an *ErrSyntax", tt.selector, err)
+		} else if err.Error() != tt.want {
+			t.Errorf("CompileSelector(%q) = %v, want %s", tt.selector, err, tt.want)
+		}
+	}
+}
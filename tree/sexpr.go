@@ -0,0 +1,483 @@
+package tree
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	common "github.com/PlayerR9/mygo-data/common"
+)
+
+// sexprFrame is a pending step of the S-expression encoder.
+type sexprFrame struct {
+	// node is the node to encode.
+	node *BaseNode
+
+	// close is true if the frame closes the node instead of opening it.
+	close bool
+}
+
+// MarshalSExpr encodes the tree rooted at root as an S-expression of the form:
+//
+//	(<type> <data> <span> <child> ...)
+//
+// Where:
+//   - <type> is the type of the node. It is written as a bare atom when
+//     possible and as a quoted string otherwise.
+//   - <data> is the quoted data of the node. It is omitted if empty.
+//   - <span> is the source span of the node, written as
+//     @"<file>":<start>-<end>. It is only written with WithSpans(true) and
+//     omitted if the node has no span. The file is omitted, along with the
+//     colon, if empty. Positions are written as <offset>:<line>:<column>, or
+//     as <offset> alone if the line is unknown.
+//   - <child> is the S-expression of a child.
+//
+// The encoding is not recursive.
+//
+// Parameters:
+//   - root: The root of the tree to encode.
+//   - opts: The options of the encoding.
+//
+// Returns:
+//   - string: The S-expression of the tree.
+//   - error: An error if the root is nil.
+//
+// Errors:
+//   - common.ErrBadParam: If the root is nil.
+func MarshalSExpr(root *BaseNode, opts ...EncodeOption) (string, error) {
+	if root == nil {
+		err := common.NewErrNilParam("root")
+		return "", err
+	}
+
+	o := newEncodeOptions(false, opts)
+
+	var builder strings.Builder
+
+	stack := []sexprFrame{{node: root}}
+
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if top.close {
+			_, _ = builder.WriteRune(')')
+			continue
+		}
+
+		if top.node.PrevSibling != nil && top.node != root {
+			_, _ = builder.WriteRune(' ')
+		}
+
+		_, _ = builder.WriteRune('(')
+
+		if isSExprAtom(top.node.Type) {
+			_, _ = builder.WriteString(top.node.Type)
+		} else {
+			_, _ = builder.WriteString(strconv.Quote(top.node.Type))
+		}
+
+		if top.node.Data != "" {
+			_, _ = builder.WriteRune(' ')
+			_, _ = builder.WriteString(strconv.Quote(top.node.Data))
+		}
+
+		if o.spans && top.node.Span != nil {
+			_, _ = builder.WriteRune(' ')
+			writeSExprSpan(&builder, *top.node.Span)
+		}
+
+		if top.node.FirstChild == nil {
+			_, _ = builder.WriteRune(')')
+			continue
+		}
+
+		_, _ = builder.WriteRune(' ')
+
+		stack = append(stack, sexprFrame{node: top.node, close: true})
+
+		for child := top.node.LastChild; child != nil; child = child.PrevSibling {
+			stack = append(stack, sexprFrame{node: child})
+		}
+	}
+
+	str := builder.String()
+	return str, nil
+}
+
+// writeSExprSpan writes the S-expression encoding of the span, as described in
+// MarshalSExpr.
+//
+// Parameters:
+//   - builder: The builder to write to.
+//   - span: The span to encode.
+func writeSExprSpan(builder *strings.Builder, span Span) {
+	_, _ = builder.WriteRune('@')
+
+	if span.File != "" {
+		_, _ = builder.WriteString(strconv.Quote(span.File))
+		_, _ = builder.WriteRune(':')
+	}
+
+	for i, pos := range []Position{span.Start, span.End} {
+		if i > 0 {
+			_, _ = builder.WriteRune('-')
+		}
+
+		_, _ = builder.WriteString(strconv.Itoa(pos.Offset))
+
+		if pos.Line > 0 {
+			_, _ = builder.WriteRune(':')
+			_, _ = builder.WriteString(strconv.Itoa(pos.Line))
+			_, _ = builder.WriteRune(':')
+			_, _ = builder.WriteString(strconv.Itoa(pos.Column))
+		}
+	}
+}
+
+// isSExprAtom checks whether the given string can be written as a bare atom.
+//
+// Parameters:
+//   - str: The string to check.
+//
+// Returns:
+//   - bool: True if the string can be written as a bare atom, false otherwise.
+func isSExprAtom(str string) bool {
+	if str == "" {
+		return false
+	}
+
+	for _, r := range str {
+		if !isSExprAtomRune(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isSExprAtomRune checks whether the given rune can be part of a bare atom.
+//
+// Parameters:
+//   - r: The rune to check.
+//
+// Returns:
+//   - bool: True if the rune can be part of a bare atom, false otherwise.
+func isSExprAtomRune(r rune) bool {
+	switch r {
+	case '(', ')', '"', ';', utf8.RuneError:
+		return false
+	default:
+		return unicode.IsGraphic(r) && !unicode.IsSpace(r)
+	}
+}
+
+// skipSExprSpace skips whitespace and comments. Comments start with ';' and
+// end at the end of the line.
+func (p *textParser) skipSExprSpace() {
+	for {
+		p.skipSpace()
+
+		if p.pos >= len(p.input) || p.input[p.pos] != ';' {
+			return
+		}
+
+		idx := strings.IndexByte(p.input[p.pos:], '\n')
+		if idx < 0 {
+			p.pos = len(p.input)
+		} else {
+			p.pos += idx + 1
+		}
+	}
+}
+
+// readAtom reads a bare atom starting at the current position.
+//
+// Returns:
+//   - string: The atom. Empty if there is no atom at the current position.
+func (p *textParser) readAtom() string {
+	start := p.pos
+
+	for p.pos < len(p.input) {
+		r, size := utf8.DecodeRuneInString(p.input[p.pos:])
+		if !isSExprAtomRune(r) {
+			break
+		}
+
+		p.pos += size
+	}
+
+	return p.input[start:p.pos]
+}
+
+// readInt reads a non-negative decimal integer starting at the current
+// position.
+//
+// Returns:
+//   - int: The integer.
+//   - error: An error if there is no integer at the current position.
+//
+// Errors:
+//   - *ErrSyntax: If there is no integer at the current position or if it is
+//     too large.
+func (p *textParser) readInt() (int, error) {
+	start := p.pos
+
+	for p.pos < len(p.input) && '0' <= p.input[p.pos] && p.input[p.pos] <= '9' {
+		p.pos++
+	}
+
+	if p.pos == start {
+		err := NewErrSyntax(p.format, start, "expected a number")
+		return 0, err
+	}
+
+	n, err := strconv.Atoi(p.input[start:p.pos])
+	if err != nil {
+		err := NewErrSyntax(p.format, start, "number out of range")
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// readPosition reads a span position starting at the current position.
+//
+// Returns:
+//   - Position: The position.
+//   - error: An error if the position is not valid.
+//
+// Errors:
+//   - *ErrSyntax: If the position is not valid.
+func (p *textParser) readPosition() (Position, error) {
+	offset, err := p.readInt()
+	if err != nil {
+		return Position{}, err
+	}
+
+	pos := Position{Offset: offset}
+
+	if p.pos >= len(p.input) || p.input[p.pos] != ':' {
+		return pos, nil
+	}
+
+	p.pos++
+
+	pos.Line, err = p.readInt()
+	if err != nil {
+		return Position{}, err
+	}
+
+	if p.pos >= len(p.input) || p.input[p.pos] != ':' {
+		err := NewErrSyntax(p.format, p.pos, "expected ':' before the column")
+		return Position{}, err
+	}
+
+	p.pos++
+
+	pos.Column, err = p.readInt()
+	if err != nil {
+		return Position{}, err
+	}
+
+	return pos, nil
+}
+
+// readSpan reads a span starting at the '@' at the current position.
+//
+// Returns:
+//   - *Span: The span. Nil if an error occurred.
+//   - error: An error if the span is not valid.
+//
+// Errors:
+//   - *ErrSyntax: If the span is not valid.
+func (p *textParser) readSpan() (*Span, error) {
+	p.pos++
+
+	span := &Span{}
+
+	if p.pos < len(p.input) && p.input[p.pos] == '"' {
+		file, err := p.readString()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.pos >= len(p.input) || p.input[p.pos] != ':' {
+			err := NewErrSyntax(p.format, p.pos, "expected ':' after the file of a span")
+			return nil, err
+		}
+
+		p.pos++
+
+		span.File = file
+	}
+
+	start, err := p.readPosition()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos >= len(p.input) || p.input[p.pos] != '-' {
+		err := NewErrSyntax(p.format, p.pos, "expected '-' between the positions of a span")
+		return nil, err
+	}
+
+	p.pos++
+
+	end, err := p.readPosition()
+	if err != nil {
+		return nil, err
+	}
+
+	span.Start = start
+	span.End = end
+
+	return span, nil
+}
+
+// sexprDecodeFrame is a node being decoded.
+type sexprDecodeFrame struct {
+	// node is the node being decoded.
+	node *BaseNode
+
+	// has_type is true if the node's type was decoded.
+	has_type bool
+
+	// done_data is true if the node can no longer receive data.
+	done_data bool
+
+	// has_span is true if the node's span was decoded.
+	has_span bool
+
+	// has_children is true if a child of the node was decoded.
+	has_children bool
+}
+
+// UnmarshalSExpr decodes a tree encoded by MarshalSExpr. Whitespace between
+// elements is ignored and ';' starts a comment that ends at the end of the
+// line. The decoding is not recursive.
+//
+// Parameters:
+//   - input: The S-expression of the tree.
+//
+// Returns:
+//   - *BaseNode: The root of the decoded tree. Nil if an error occurred.
+//   - error: An error if the input is not a valid encoding of a tree.
+//
+// Errors:
+//   - *ErrSyntax: If the input is not a valid encoding of a tree.
+func UnmarshalSExpr(input string) (*BaseNode, error) {
+	p := &textParser{format: "S-expression", input: input}
+
+	p.skipSExprSpace()
+
+	if p.pos >= len(p.input) || p.input[p.pos] != '(' {
+		err := NewErrSyntax(p.format, p.pos, "expected '('")
+		return nil, err
+	}
+
+	p.pos++
+
+	root := &BaseNode{}
+
+	stack := []*sexprDecodeFrame{{node: root}}
+
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+
+		p.skipSExprSpace()
+
+		if p.pos >= len(p.input) {
+			err := NewErrSyntax(p.format, p.pos, "unexpected end of input")
+			return nil, err
+		}
+
+		c := p.input[p.pos]
+
+		switch {
+		case !top.has_type:
+			var str string
+
+			if c == '"' {
+				s, err := p.readString()
+				if err != nil {
+					return nil, err
+				}
+
+				str = s
+			} else {
+				str = p.readAtom()
+				if str == "" {
+					err := NewErrSyntax(p.format, p.pos, "expected a node type")
+					return nil, err
+				}
+			}
+
+			top.node.Type = str
+			top.has_type = true
+		case c == '"':
+			if top.done_data {
+				reason := "data after the children of a node"
+
+				if top.has_span && !top.has_children {
+					reason = "data after the span of a node"
+				}
+
+				err := NewErrSyntax(p.format, p.pos, reason)
+				return nil, err
+			}
+
+			str, err := p.readString()
+			if err != nil {
+				return nil, err
+			}
+
+			top.node.Data = str
+			top.done_data = true
+		case c == '@':
+			if top.has_children {
+				err := NewErrSyntax(p.format, p.pos, "span after the children of a node")
+				return nil, err
+			} else if top.has_span {
+				err := NewErrSyntax(p.format, p.pos, "node with two spans")
+				return nil, err
+			}
+
+			span, err := p.readSpan()
+			if err != nil {
+				return nil, err
+			}
+
+			top.node.Span = span
+			top.has_span = true
+			top.done_data = true
+		case c == '(':
+			p.pos++
+
+			top.done_data = true
+			top.has_children = true
+
+			child := &BaseNode{}
+			_ = top.node.AppendChild(child)
+
+			stack = append(stack, &sexprDecodeFrame{node: child})
+		case c == ')':
+			p.pos++
+
+			stack = stack[:len(stack)-1]
+		default:
+			err := NewErrSyntax(p.format, p.pos, "expected data, a span, a child node or ')'")
+			return nil, err
+		}
+	}
+
+	p.skipSExprSpace()
+
+	if p.pos < len(p.input) {
+		err := NewErrSyntax(p.format, p.pos, "unexpected data after the root node")
+		return nil, err
+	}
+
+	return root, nil
+}
@@ -0,0 +1,123 @@
+package tree
+
+import (
+	"errors"
+	"math/rand/v2"
+	"testing"
+)
+
+// roundTripTrees returns the trees the encoders must round-trip: odd types and
+// data, a deep tree, a wide tree and random trees.
+//
+// Parameters:
+//   - depth: The number of nodes of the deep tree.
+//
+// Returns:
+//   - []*BaseNode: The roots of the trees.
+func roundTripTrees(depth int) []*BaseNode {
+	labels := []string{
+		"", "A", "a b", "(", ")", `"`, `\`, ";", "@", "\n\t", "é✓", "\x00", `say "hi"\n`,
+	}
+
+	odd := NewBaseNode("Root", "")
+
+	for _, type_ := range labels {
+		for _, data := range labels {
+			_ = odd.AppendChild(NewBaseNode(type_, data))
+		}
+	}
+
+	deep := NewBaseNode("Deep", "0")
+
+	leaf := deep
+
+	for range depth - 1 {
+		child := NewBaseNode("Deep", "")
+		_ = leaf.AppendChild(child)
+
+		leaf = child
+	}
+
+	wide := NewBaseNode("Wide", "")
+
+	for range 1000 {
+		_ = wide.AppendChild(NewBaseNode("Leaf", "x"))
+	}
+
+	trees := []*BaseNode{NewBaseNode("", ""), odd, deep, wide}
+
+	rng := rand.New(rand.NewPCG(5, 5))
+
+	for range 50 {
+		trees = append(trees, randomTree(rng, 1+rng.IntN(40)))
+	}
+
+	return trees
+}
+
+// TestSExprRoundTrip checks that UnmarshalSExpr decodes exactly the trees
+// encoded by MarshalSExpr.
+func TestSExprRoundTrip(t *testing.T) {
+	for _, root := range roundTripTrees(10000) {
+		str := sexprOf(t, root)
+
+		decoded := mustSExpr(t, str)
+
+		if !Equal(root, decoded) {
+			t.Fatalf("%s decoded as %s", str, sexprOf(t, decoded))
+		}
+
+		if err := Validate(decoded); err != nil {
+			t.Fatalf("%s decoded as an invalid tree: %v", str, err)
+		}
+	}
+}
+
+// TestSExprComments checks that whitespace and comments are skipped anywhere
+// between elements.
+func TestSExprComments(t *testing.T) {
+	input := "; leading comment\n( A ; type\n\t\"x\" ; data\n (B) ;child\n) ; trailing"
+
+	if got, want := sexprOf(t, mustSExpr(t, input)), `(A "x" (B))`; got != want {
+		t.Fatalf("UnmarshalSExpr(%q) = %s, want %s", input, got, want)
+	}
+}
+
+// TestSExprSyntaxErrors checks the offsets of the syntax errors reported for
+// malformed input.
+func TestSExprSyntaxErrors(t *testing.T) {
+	tests := []struct {
+		input  string
+		offset int
+	}{
+		{``, 0},
+		{`A`, 0},
+		{`  ()`, 3},
+		{`(A`, 2},
+		{`(A (B)`, 6},
+		{`(A "x`, 3},
+		{`(A "\q")`, 3},
+		{`(A "x" "y")`, 7},
+		{`(A (B) "x")`, 7},
+		{`(A [)`, 3},
+		{`(A) (B)`, 4},
+		{`(A) ; comment` + "\n" + `x`, 14},
+		{`(A @x-1)`, 4},
+		{`(A @1:2-3)`, 7},
+		{`(A @1 2)`, 5},
+		{`(A @"f"1-2)`, 7},
+		{`(A @99999999999999999999-1)`, 4},
+	}
+
+	for _, tt := range tests {
+		_, err := UnmarshalSExpr(tt.input)
+
+		var syntax_err *ErrSyntax
+
+		if !errors.As(err, &syntax_err) {
+			t.Errorf("UnmarshalSExpr(%q) = %v, want an *ErrSyntax", tt.input, err)
+		} else if syntax_err.Offset != tt.offset || syntax_err.Format != "S-expression" {
+			t.Errorf("UnmarshalSExpr(%q) = %v, want an S-expression error at offset %d", tt.input, err, tt.offset)
+		}
+	}
+}
@@ -0,0 +1,364 @@
+package tree
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Position is a position in a source input.
+type Position struct {
+	// Offset is the byte offset, starting from 0.
+	Offset int `json:"offset"`
+
+	// Line is the line number, starting from 1. Zero if unknown.
+	Line int `json:"line,omitempty"`
+
+	// Column is the column number in bytes, starting from 1. Zero if unknown.
+	Column int `json:"column,omitempty"`
+}
+
+// String implements fmt.Stringer.
+//
+// Format:
+//
+//	"<line>:<column>"
+//	"#<offset>"
+//
+// The second form is used when the line is unknown.
+func (p Position) String() string {
+	if p.Line <= 0 {
+		return "#" + strconv.Itoa(p.Offset)
+	}
+
+	return strconv.Itoa(p.Line) + ":" + strconv.Itoa(p.Column)
+}
+
+// Span is the range of a source input a node was parsed from. The range is
+// half-open: it starts at Start and ends right before End.
+type Span struct {
+	// File is the name of the source file. Empty if unknown.
+	File string `json:"file,omitempty"`
+
+	// Start is the position of the first byte of the range.
+	Start Position `json:"start"`
+
+	// End is the position right after the last byte of the range.
+	End Position `json:"end"`
+}
+
+// String implements fmt.Stringer.
+//
+// Format:
+//
+//	"<file>:<start>-<end>"
+//
+// Where:
+//   - <file> is the name of the file. If empty, it is omitted along with the
+//     colon.
+//   - <start> and <end> are the positions, as formatted by Position.String.
+func (s Span) String() string {
+	var builder strings.Builder
+
+	if s.File != "" {
+		_, _ = builder.WriteString(s.File)
+		_, _ = builder.WriteRune(':')
+	}
+
+	_, _ = builder.WriteString(s.Start.String())
+	_, _ = builder.WriteRune('-')
+	_, _ = builder.WriteString(s.End.String())
+
+	str := builder.String()
+	return str
+}
+
+// Contains checks whether the span contains the given offset. An empty span
+// only contains its start offset.
+//
+// Parameters:
+//   - offset: The byte offset to check.
+//
+// Returns:
+//   - bool: True if the span contains the offset, false otherwise.
+func (s Span) Contains(offset int) bool {
+	if s.Start.Offset == s.End.Offset {
+		return offset == s.Start.Offset
+	}
+
+	return s.Start.Offset <= offset && offset < s.End.Offset
+}
+
+// Union returns the smallest span that covers both spans. The file of the
+// receiver is kept.
+//
+// Parameters:
+//   - other: The other span.
+//
+// Returns:
+//   - Span: The union of the spans.
+func (s Span) Union(other Span) Span {
+	if other.Start.Offset < s.Start.Offset {
+		s.Start = other.Start
+	}
+
+	if other.End.Offset > s.End.Offset {
+		s.End = other.End
+	}
+
+	return s
+}
+
+// sourceSpan returns the span of the node.
+//
+// Returns:
+//   - *Span: The span of the node. Nil if the node has none.
+func (n *GenericNode[T, D]) sourceSpan() *Span {
+	if n == nil {
+		return nil
+	}
+
+	return n.Span
+}
+
+// LineIndex converts byte offsets of a source input into line and column
+// numbers.
+type LineIndex struct {
+	// file is the name of the source file.
+	file string
+
+	// starts are the offsets at which each line starts.
+	starts []int
+
+	// size is the size of the input.
+	size int
+}
+
+// NewLineIndex indexes the lines of the given source input. Lines are
+// separated by '\n'.
+//
+// Parameters:
+//   - file: The name of the source file. May be empty.
+//   - src: The source input.
+//
+// Returns:
+//   - *LineIndex: The line index. Never returns nil.
+func NewLineIndex(file, src string) *LineIndex {
+	starts := []int{0}
+
+	for i := 0; i < len(src); i++ {
+		if src[i] == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+
+	li := &LineIndex{
+		file:   file,
+		starts: starts,
+		size:   len(src),
+	}
+
+	return li
+}
+
+// Position returns the position of the given offset. Offsets out of the
+// input are clamped to it.
+//
+// Parameters:
+//   - offset: The byte offset.
+//
+// Returns:
+//   - Position: The position of the offset, with its line and column. The
+//     line and column are unknown if the receiver is nil.
+func (li *LineIndex) Position(offset int) Position {
+	if li == nil {
+		return Position{Offset: offset}
+	}
+
+	offset = max(0, min(offset, li.size))
+
+	idx, found := slices.BinarySearch(li.starts, offset)
+	if !found {
+		idx--
+	}
+
+	pos := Position{
+		Offset: offset,
+		Line:   idx + 1,
+		Column: offset - li.starts[idx] + 1,
+	}
+
+	return pos
+}
+
+// Span returns the span between the given offsets, in the indexed file.
+//
+// Parameters:
+//   - start: The offset of the first byte of the range.
+//   - end: The offset right after the last byte of the range.
+//
+// Returns:
+//   - *Span: The span. Never returns nil.
+func (li *LineIndex) Span(start, end int) *Span {
+	var file string
+
+	if li != nil {
+		file = li.file
+	}
+
+	s := &Span{
+		File:  file,
+		Start: li.Position(start),
+		End:   li.Position(end),
+	}
+
+	return s
+}
+
+// UnionSpans sets the span of every node of the tree rooted at root to the
+// union of its own span and of the spans of its children, so that parents
+// built from children cover them. Nodes without span whose children have none
+// are left without span. Every node gets a span of its own: spans are
+// replaced, not modified in place, and never shared between nodes. The
+// computation is not recursive.
+//
+// Parameters:
+//   - root: The root of the tree.
+func UnionSpans[T comparable, D any](root *GenericNode[T, D]) {
+	if root == nil {
+		return
+	}
+
+	for n := range PostOrder(root) {
+		unionChildSpans(n)
+	}
+}
+
+// unionChildSpans sets the span of the node to the union of its own span and
+// of the spans of its children. The span is replaced, not modified in place.
+//
+// Parameters:
+//   - n: The node. Assumed to be non-nil.
+func unionChildSpans[T comparable, D any](n *GenericNode[T, D]) {
+	var union *Span
+
+	if n.Span != nil {
+		s := *n.Span
+		union = &s
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Span == nil {
+			continue
+		}
+
+		if union == nil {
+			s := *c.Span
+			union = &s
+		} else {
+			*union = union.Union(*c.Span)
+		}
+	}
+
+	n.Span = union
+}
+
+// NodeAtOffset returns the deepest node of the tree rooted at root whose span
+// contains the given offset. Nodes without span are searched through, but
+// subtrees whose root has a span that does not contain the offset are
+// skipped. If several nodes at the same depth contain the offset, the first
+// one in pre-order is returned. The search is not recursive.
+//
+// Parameters:
+//   - root: The root of the tree to search.
+//   - offset: The byte offset to look up.
+//
+// Returns:
+//   - *GenericNode[T, D]: The deepest node covering the offset. Nil if there
+//     is none.
+func NodeAtOffset[T comparable, D any](root *GenericNode[T, D], offset int) *GenericNode[T, D] {
+	if root == nil {
+		return nil
+	}
+
+	type frame struct {
+		// node is the node to check.
+		node *GenericNode[T, D]
+
+		// depth is the depth of the node.
+		depth int
+	}
+
+	var best *GenericNode[T, D]
+	best_depth := -1
+
+	stack := []frame{{node: root}}
+
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if top.node.Span != nil {
+			if !top.node.Span.Contains(offset) {
+				continue
+			}
+
+			if top.depth > best_depth {
+				best = top.node
+				best_depth = top.depth
+			}
+		}
+
+		for c := top.node.LastChild; c != nil; c = c.PrevSibling {
+			stack = append(stack, frame{node: c, depth: top.depth + 1})
+		}
+	}
+
+	return best
+}
+
+// EncodeOption is an option of TreeToString, MarshalJSON and MarshalSExpr.
+type EncodeOption func(opts *encodeOptions)
+
+// encodeOptions are the options of an encoder.
+type encodeOptions struct {
+	// spans is true if the source spans of the nodes are written.
+	spans bool
+}
+
+// WithSpans tells whether the source spans of the nodes are written. By
+// default, MarshalJSON writes them while TreeToString and MarshalSExpr do
+// not.
+//
+// Parameters:
+//   - enabled: True to write the spans, false to omit them.
+//
+// Returns:
+//   - EncodeOption: The option. Never returns nil.
+func WithSpans(enabled bool) EncodeOption {
+	return func(opts *encodeOptions) {
+		opts.spans = enabled
+	}
+}
+
+// newEncodeOptions applies the options on top of the defaults of an encoder.
+//
+// Parameters:
+//   - spans: Whether the encoder writes spans by default.
+//   - opts: The options to apply. Nil options are ignored.
+//
+// Returns:
+//   - encodeOptions: The resulting options.
+func newEncodeOptions(spans bool, opts []EncodeOption) encodeOptions {
+	o := encodeOptions{
+		spans: spans,
+	}
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+
+	return o
+}
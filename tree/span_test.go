@@ -0,0 +1,167 @@
+package tree
+
+import (
+	"strings"
+	"testing"
+)
+
+// spannedTree returns a small tree whose nodes have spans from a line index.
+//
+// Returns:
+//   - *BaseNode: The root of the tree. Never returns nil.
+func spannedTree() *BaseNode {
+	li := NewLineIndex("main.go", "a + b\nc")
+
+	lhs := NewBaseNode("Ident", "a")
+	lhs.Span = li.Span(0, 1)
+
+	rhs := NewBaseNode("Ident", "b")
+	rhs.Span = li.Span(4, 5)
+
+	// The root has no span of its own; the leaf has one without line.
+	leaf := NewBaseNode("Leaf", "")
+	leaf.Span = &Span{Start: Position{Offset: 6}, End: Position{Offset: 7}}
+
+	root := NewBaseNode("Add", "+")
+	_ = AppendChildren(root, []*BaseNode{lhs, rhs, leaf})
+
+	return root
+}
+
+// TestSExprSpans checks that MarshalSExpr only writes spans when asked to and
+// that UnmarshalSExpr decodes them back.
+func TestSExprSpans(t *testing.T) {
+	root := spannedTree()
+
+	plain := sexprOf(t, root)
+	if strings.Contains(plain, "@") {
+		t.Fatalf("MarshalSExpr wrote spans by default: %s", plain)
+	}
+
+	str, err := MarshalSExpr(root, WithSpans(true))
+	if err != nil {
+		t.Fatalf("MarshalSExpr returned %v", err)
+	}
+
+	want := `(Add "+" (Ident "a" @"main.go":0:1:1-1:1:2) (Ident "b" @"main.go":4:1:5-5:1:6) (Leaf @6-7))`
+	if str != want {
+		t.Fatalf("MarshalSExpr = %s, want %s", str, want)
+	}
+
+	decoded := mustSExpr(t, str)
+
+	for a, b := range zipPreOrder(root, decoded) {
+		if (a.Span == nil) != (b.Span == nil) || a.Span != nil && *a.Span != *b.Span {
+			t.Fatalf("span of %v decoded as %v, want %v", a, b.Span, a.Span)
+		}
+	}
+}
+
+// TestSExprSpanErrors checks that misplaced or malformed spans are rejected.
+func TestSExprSpanErrors(t *testing.T) {
+	inputs := []string{
+		`(A (B) @0-1)`,
+		`(A @0-1 @0-1)`,
+		`(A @0-1 "data")`,
+		`(A @0)`,
+		`(A @0:1-2)`,
+		`(A @"f"0-1)`,
+	}
+
+	for _, input := range inputs {
+		_, err := UnmarshalSExpr(input)
+		if err == nil {
+			t.Errorf("UnmarshalSExpr(%q) succeeded", input)
+		}
+	}
+}
+
+// TestJSONSpans checks that MarshalJSON writes spans by default and omits them
+// with WithSpans(false).
+func TestJSONSpans(t *testing.T) {
+	root := spannedTree()
+
+	data, err := MarshalJSON(root)
+	if err != nil {
+		t.Fatalf("MarshalJSON returned %v", err)
+	}
+
+	if !strings.Contains(string(data), `"span"`) {
+		t.Fatalf("MarshalJSON omitted the spans: %s", data)
+	}
+
+	data, err = MarshalJSON(root, WithSpans(false))
+	if err != nil {
+		t.Fatalf("MarshalJSON returned %v", err)
+	}
+
+	if strings.Contains(string(data), `"span"`) {
+		t.Fatalf("MarshalJSON wrote spans: %s", data)
+	}
+}
+
+// TestTreeToStringSpans checks that TreeToString appends the spans only when
+// asked to.
+func TestTreeToStringSpans(t *testing.T) {
+	root := spannedTree()
+
+	if str := TreeToString(root); strings.Contains(str, " @ ") {
+		t.Fatalf("TreeToString wrote spans by default:\n%s", str)
+	}
+
+	str := TreeToString(root, WithSpans(true))
+
+	if !strings.Contains(str, `Node[Ident ("a")] @ main.go:1:1-1:2`) || !strings.Contains(str, "Node[Leaf] @ #6-#7") {
+		t.Fatalf("TreeToString did not write the spans:\n%s", str)
+	}
+}
+
+// TestUnionSpans checks that every node gets a span of its own covering its
+// children.
+func TestUnionSpans(t *testing.T) {
+	root := spannedTree()
+
+	UnionSpans(root)
+
+	if root.Span == nil || root.Span.Start.Offset != 0 || root.Span.End.Offset != 7 {
+		t.Fatalf("root span = %v, want 0-7", root.Span)
+	}
+
+	seen := make(map[*Span]bool)
+
+	for n := range PreOrder(root) {
+		if seen[n.Span] {
+			t.Fatalf("the span of %v is shared", n)
+		}
+
+		seen[n.Span] = true
+	}
+}
+
+// zipPreOrder pairs the nodes of two trees of the same shape in pre-order.
+//
+// Parameters:
+//   - a: The root of the first tree.
+//   - b: The root of the second tree.
+//
+// Returns:
+//   - map[*BaseNode]*BaseNode: The nodes of a mapped to the nodes of b.
+func zipPreOrder(a, b *BaseNode) map[*BaseNode]*BaseNode {
+	var as, bs []*BaseNode
+
+	for n := range PreOrder(a) {
+		as = append(as, n)
+	}
+
+	for n := range PreOrder(b) {
+		bs = append(bs, n)
+	}
+
+	pairs := make(map[*BaseNode]*BaseNode)
+
+	for i := range min(len(as), len(bs)) {
+		pairs[as[i]] = bs[i]
+	}
+
+	return pairs
+}
@@ -0,0 +1,234 @@
+package tree
+
+import "iter"
+
+// PreOrder returns an iterator over the nodes of the tree in pre-order (a node
+// is visited before its children). The traversal is not recursive.
+//
+// Parameters:
+//   - root: The root of the tree to traverse.
+//
+// Returns:
+//   - iter.Seq[T]: An iterator over the nodes of the tree. Never returns nil.
+func PreOrder[T interface {
+	Children() []T
+}](root T) iter.Seq[T] {
+	fn := func(yield func(T) bool) {
+		stack := []T{root}
+
+		for len(stack) > 0 {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			if !yield(top) {
+				return
+			}
+
+			children := top.Children()
+
+			for i := len(children) - 1; i >= 0; i-- {
+				stack = append(stack, children[i])
+			}
+		}
+	}
+
+	return fn
+}
+
+// postOrderFrame is a frame of the post-order traversal.
+type postOrderFrame[T any] struct {
+	// node is the node of the frame.
+	node T
+
+	// children are the children of the node.
+	children []T
+
+	// idx is the index of the next child to visit.
+	idx int
+}
+
+// PostOrder returns an iterator over the nodes of the tree in post-order (a
+// node is visited after its children). The traversal is not recursive.
+//
+// Parameters:
+//   - root: The root of the tree to traverse.
+//
+// Returns:
+//   - iter.Seq[T]: An iterator over the nodes of the tree. Never returns nil.
+func PostOrder[T interface {
+	Children() []T
+}](root T) iter.Seq[T] {
+	fn := func(yield func(T) bool) {
+		stack := []*postOrderFrame[T]{
+			{node: root, children: root.Children()},
+		}
+
+		for len(stack) > 0 {
+			top := stack[len(stack)-1]
+
+			if top.idx < len(top.children) {
+				child := top.children[top.idx]
+				top.idx++
+
+				stack = append(stack, &postOrderFrame[T]{
+					node:     child,
+					children: child.Children(),
+				})
+
+				continue
+			}
+
+			stack = stack[:len(stack)-1]
+
+			if !yield(top.node) {
+				return
+			}
+		}
+	}
+
+	return fn
+}
+
+// LevelOrder returns an iterator over the nodes of the tree in level-order
+// (breadth-first). The traversal is not recursive.
+//
+// Parameters:
+//   - root: The root of the tree to traverse.
+//
+// Returns:
+//   - iter.Seq[T]: An iterator over the nodes of the tree. Never returns nil.
+func LevelOrder[T interface {
+	Children() []T
+}](root T) iter.Seq[T] {
+	fn := func(yield func(T) bool) {
+		queue := []T{root}
+
+		for len(queue) > 0 {
+			first := queue[0]
+
+			var zero T
+			queue[0] = zero
+			queue = queue[1:]
+
+			if !yield(first) {
+				return
+			}
+
+			queue = append(queue, first.Children()...)
+		}
+	}
+
+	return fn
+}
+
+// Descendants returns an iterator over the descendants of the root, in
+// pre-order, together with their depth relative to the root. The root itself
+// is not yielded; its children have depth 1. The traversal is not recursive.
+//
+// Parameters:
+//   - root: The root of the tree to traverse.
+//
+// Returns:
+//   - iter.Seq2[T, int]: An iterator over the descendants and their depths.
+//     Never returns nil.
+func Descendants[T interface {
+	Children() []T
+}](root T) iter.Seq2[T, int] {
+	type frame struct {
+		node  T
+		depth int
+	}
+
+	fn := func(yield func(T, int) bool) {
+		var stack []frame
+
+		children := root.Children()
+
+		for i := len(children) - 1; i >= 0; i-- {
+			stack = append(stack, frame{node: children[i], depth: 1})
+		}
+
+		for len(stack) > 0 {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			if !yield(top.node, top.depth) {
+				return
+			}
+
+			children := top.node.Children()
+
+			for i := len(children) - 1; i >= 0; i-- {
+				stack = append(stack, frame{node: children[i], depth: top.depth + 1})
+			}
+		}
+	}
+
+	return fn
+}
+
+// Ancestors returns an iterator over the ancestors of the node, from its parent
+// up to the root. The node itself is not yielded.
+//
+// Returns:
+//   - iter.Seq[*GenericNode[T, D]]: An iterator over the ancestors of the node.
+//     Never returns nil.
+func (n *GenericNode[T, D]) Ancestors() iter.Seq[*GenericNode[T, D]] {
+	if n == nil || n.Parent == nil {
+		return func(yield func(*GenericNode[T, D]) bool) {}
+	}
+
+	fn := func(yield func(*GenericNode[T, D]) bool) {
+		for p := n.Parent; p != nil; p = p.Parent {
+			if !yield(p) {
+				return
+			}
+		}
+	}
+
+	return fn
+}
+
+// FollowingSiblings returns an iterator over the siblings that come after the
+// node, from the nearest to the farthest. The node itself is not yielded.
+//
+// Returns:
+//   - iter.Seq[*GenericNode[T, D]]: An iterator over the following siblings.
+//     Never returns nil.
+func (n *GenericNode[T, D]) FollowingSiblings() iter.Seq[*GenericNode[T, D]] {
+	if n == nil || n.NextSibling == nil {
+		return func(yield func(*GenericNode[T, D]) bool) {}
+	}
+
+	fn := func(yield func(*GenericNode[T, D]) bool) {
+		for s := n.NextSibling; s != nil; s = s.NextSibling {
+			if !yield(s) {
+				return
+			}
+		}
+	}
+
+	return fn
+}
+
+// PrecedingSiblings returns an iterator over the siblings that come before the
+// node, from the nearest to the farthest. The node itself is not yielded.
+//
+// Returns:
+//   - iter.Seq[*GenericNode[T, D]]: An iterator over the preceding siblings.
+//     Never returns nil.
+func (n *GenericNode[T, D]) PrecedingSiblings() iter.Seq[*GenericNode[T, D]] {
+	if n == nil || n.PrevSibling == nil {
+		return func(yield func(*GenericNode[T, D]) bool) {}
+	}
+
+	fn := func(yield func(*GenericNode[T, D]) bool) {
+		for s := n.PrevSibling; s != nil; s = s.PrevSibling {
+			if !yield(s) {
+				return
+			}
+		}
+	}
+
+	return fn
+}
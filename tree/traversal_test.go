@@ -0,0 +1,118 @@
+package tree
+
+import (
+	"iter"
+	"slices"
+	"testing"
+)
+
+// typesOf collects the types of at most limit nodes of the sequence, stopping
+// the iteration early once the limit is reached.
+//
+// Parameters:
+//   - seq: The sequence of nodes.
+//   - limit: The maximum number of nodes to collect. If negative, every node
+//     is collected.
+//
+// Returns:
+//   - []string: The types of the collected nodes, in order.
+func typesOf(seq iter.Seq[*BaseNode], limit int) []string {
+	var types []string
+
+	for n := range seq {
+		if len(types) == limit {
+			break
+		}
+
+		types = append(types, n.Type)
+	}
+
+	return types
+}
+
+// TestTraversals checks the order of PreOrder, PostOrder and LevelOrder, and
+// that they stop as soon as the loop body breaks.
+func TestTraversals(t *testing.T) {
+	root := mustSExpr(t, `(A (B (D) (E (G))) (C (F)))`)
+
+	tests := []struct {
+		name string
+		seq  iter.Seq[*BaseNode]
+		want []string
+	}{
+		{"PreOrder", PreOrder(root), []string{"A", "B", "D", "E", "G", "C", "F"}},
+		{"PostOrder", PostOrder(root), []string{"D", "G", "E", "B", "F", "C", "A"}},
+		{"LevelOrder", LevelOrder(root), []string{"A", "B", "C", "D", "E", "F", "G"}},
+		{"Ancestors", root.FirstChild.LastChild.FirstChild.Ancestors(), []string{"E", "B", "A"}},
+		{"FollowingSiblings", root.FirstChild.FirstChild.FollowingSiblings(), []string{"E"}},
+		{"PrecedingSiblings", root.LastChild.PrecedingSiblings(), []string{"B"}},
+		{"Ancestors of the root", root.Ancestors(), nil},
+	}
+
+	for _, tt := range tests {
+		if got := typesOf(tt.seq, -1); !slices.Equal(got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+		}
+
+		// Breaking early panics if the iterator keeps yielding.
+		for limit := range len(tt.want) {
+			if got := typesOf(tt.seq, limit); !slices.Equal(got, tt.want[:limit]) {
+				t.Errorf("%s stopped after %d nodes = %v, want %v", tt.name, limit, got, tt.want[:limit])
+			}
+		}
+	}
+}
+
+// TestDescendants checks that Descendants yields the descendants in pre-order
+// with their depth, and not the root.
+func TestDescendants(t *testing.T) {
+	root := mustSExpr(t, `(A (B (D) (E (G))) (C (F)))`)
+
+	var types []string
+	var depths []int
+
+	for n, depth := range Descendants(root) {
+		types = append(types, n.Type)
+		depths = append(depths, depth)
+	}
+
+	if want := []string{"B", "D", "E", "G", "C", "F"}; !slices.Equal(types, want) {
+		t.Fatalf("Descendants = %v, want %v", types, want)
+	}
+
+	if want := []int{1, 2, 2, 3, 1, 2}; !slices.Equal(depths, want) {
+		t.Fatalf("Descendants depths = %v, want %v", depths, want)
+	}
+
+	for n := range Descendants(root) {
+		if n.Type != "B" {
+			t.Fatalf("Descendants yielded %v after the loop broke", n)
+		}
+
+		break
+	}
+}
+
+// TestTraversalDeep checks that the traversals do not recurse on a deep tree.
+func TestTraversalDeep(t *testing.T) {
+	root := NewBaseNode("N", "")
+
+	leaf := root
+
+	for range 100000 {
+		child := NewBaseNode("N", "")
+		_ = leaf.AppendChild(child)
+
+		leaf = child
+	}
+
+	for name, seq := range map[string]iter.Seq[*BaseNode]{
+		"PreOrder":   PreOrder(root),
+		"PostOrder":  PostOrder(root),
+		"LevelOrder": LevelOrder(root),
+	} {
+		if got := len(typesOf(seq, -1)); got != 100001 {
+			t.Errorf("%s yielded %d nodes, want 100001", name, got)
+		}
+	}
+}
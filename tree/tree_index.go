@@ -0,0 +1,269 @@
+package tree
+
+import (
+	"math/bits"
+)
+
+// TreeIndex answers structural queries on a frozen tree in constant or
+// logarithmic time: depth, subtree size, ancestry, k-th ancestor and lowest
+// common ancestor. It numbers the nodes in pre-order, so that a subtree is a
+// contiguous range of numbers, and keeps a binary lifting table of the
+// ancestors.
+//
+// The index is a snapshot: the results are unspecified if the tree is
+// modified after the index is built.
+type TreeIndex[T comparable, D any] struct {
+	// ids are the pre-order numbers of the nodes.
+	ids map[*GenericNode[T, D]]int32
+
+	// nodes are the nodes, indexed by their pre-order number.
+	nodes []*GenericNode[T, D]
+
+	// depths are the depths of the nodes, indexed by their pre-order number.
+	depths []int32
+
+	// sizes are the sizes of the subtrees, indexed by their pre-order number.
+	sizes []int32
+
+	// up are the ancestors: up[k][i] is the 2^k-th ancestor of the node i, or
+	// -1 if there is none.
+	up [][]int32
+
+	// height is the height of the tree.
+	height int
+}
+
+// NewTreeIndex builds the index of the tree rooted at root. The construction
+// takes O(n log n) time and space and is not recursive.
+//
+// Parameters:
+//   - root: The root of the tree to index.
+//
+// Returns:
+//   - *TreeIndex[T, D]: The index. Nil if the root is nil.
+func NewTreeIndex[T comparable, D any](root *GenericNode[T, D]) *TreeIndex[T, D] {
+	if root == nil {
+		return nil
+	}
+
+	idx := &TreeIndex[T, D]{
+		ids: make(map[*GenericNode[T, D]]int32),
+	}
+
+	var parents []int32
+
+	for n := range PreOrder(root) {
+		id := int32(len(idx.nodes))
+
+		idx.ids[n] = id
+		idx.nodes = append(idx.nodes, n)
+
+		if n == root {
+			parents = append(parents, -1)
+			idx.depths = append(idx.depths, 0)
+		} else {
+			p := idx.ids[n.Parent]
+
+			parents = append(parents, p)
+			idx.depths = append(idx.depths, idx.depths[p]+1)
+		}
+
+		idx.height = max(idx.height, int(idx.depths[id]))
+	}
+
+	idx.sizes = make([]int32, len(idx.nodes))
+
+	for i := len(idx.nodes) - 1; i >= 0; i-- {
+		idx.sizes[i]++
+
+		if p := parents[i]; p >= 0 {
+			idx.sizes[p] += idx.sizes[i]
+		}
+	}
+
+	levels := max(1, bits.Len(uint(idx.height)))
+
+	idx.up = make([][]int32, levels)
+	idx.up[0] = parents
+
+	for k := 1; k < levels; k++ {
+		prev := idx.up[k-1]
+		curr := make([]int32, len(idx.nodes))
+
+		for i, a := range prev {
+			if a < 0 {
+				curr[i] = -1
+			} else {
+				curr[i] = prev[a]
+			}
+		}
+
+		idx.up[k] = curr
+	}
+
+	return idx
+}
+
+// Len returns the number of indexed nodes.
+//
+// Returns:
+//   - int: The number of indexed nodes.
+func (idx *TreeIndex[T, D]) Len() int {
+	if idx == nil {
+		return 0
+	}
+
+	return len(idx.nodes)
+}
+
+// Height returns the height of the indexed tree.
+//
+// Returns:
+//   - int: The height of the tree. -1 if the receiver is nil.
+func (idx *TreeIndex[T, D]) Height() int {
+	if idx == nil {
+		return -1
+	}
+
+	return idx.height
+}
+
+// id returns the pre-order number of the node.
+//
+// Parameters:
+//   - node: The node.
+//
+// Returns:
+//   - int32: The pre-order number of the node.
+//   - bool: True if the node is indexed, false otherwise.
+func (idx *TreeIndex[T, D]) id(node *GenericNode[T, D]) (int32, bool) {
+	if idx == nil {
+		return 0, false
+	}
+
+	id, ok := idx.ids[node]
+	return id, ok
+}
+
+// Depth returns the depth of the node, relative to the indexed root.
+//
+// Parameters:
+//   - node: The node.
+//
+// Returns:
+//   - int: The depth of the node. -1 if the node is not indexed.
+func (idx *TreeIndex[T, D]) Depth(node *GenericNode[T, D]) int {
+	id, ok := idx.id(node)
+	if !ok {
+		return -1
+	}
+
+	return int(idx.depths[id])
+}
+
+// Size returns the number of nodes of the subtree rooted at the node, the node
+// included.
+//
+// Parameters:
+//   - node: The node.
+//
+// Returns:
+//   - int: The size of the subtree. Zero if the node is not indexed.
+func (idx *TreeIndex[T, D]) Size(node *GenericNode[T, D]) int {
+	id, ok := idx.id(node)
+	if !ok {
+		return 0
+	}
+
+	return int(idx.sizes[id])
+}
+
+// IsAncestor checks whether a is an ancestor of b, in constant time. A node is
+// considered an ancestor of itself.
+//
+// Parameters:
+//   - a: The candidate ancestor.
+//   - b: The candidate descendant.
+//
+// Returns:
+//   - bool: True if a is an ancestor of b, false otherwise. False if a node is
+//     not indexed.
+func (idx *TreeIndex[T, D]) IsAncestor(a, b *GenericNode[T, D]) bool {
+	ia, ok := idx.id(a)
+	if !ok {
+		return false
+	}
+
+	ib, ok := idx.id(b)
+	if !ok {
+		return false
+	}
+
+	return ia <= ib && ib < ia+idx.sizes[ia]
+}
+
+// Ancestor returns the k-th ancestor of the node, in logarithmic time.
+//
+// Parameters:
+//   - node: The node.
+//   - k: The number of levels to go up. Zero returns the node itself.
+//
+// Returns:
+//   - *GenericNode[T, D]: The k-th ancestor. Nil if the node is not indexed,
+//     if k is negative or if k is greater than the depth of the node.
+func (idx *TreeIndex[T, D]) Ancestor(node *GenericNode[T, D], k int) *GenericNode[T, D] {
+	id, ok := idx.id(node)
+	if !ok || k < 0 || k > int(idx.depths[id]) {
+		return nil
+	}
+
+	for level := 0; k > 0; level, k = level+1, k>>1 {
+		if k&1 == 1 {
+			id = idx.up[level][id]
+		}
+	}
+
+	return idx.nodes[id]
+}
+
+// LCA returns the lowest common ancestor of two nodes, in logarithmic time. A
+// node is considered an ancestor of itself.
+//
+// Parameters:
+//   - a: The first node.
+//   - b: The second node.
+//
+// Returns:
+//   - *GenericNode[T, D]: The lowest common ancestor. Nil if a node is not
+//     indexed.
+func (idx *TreeIndex[T, D]) LCA(a, b *GenericNode[T, D]) *GenericNode[T, D] {
+	ia, ok := idx.id(a)
+	if !ok {
+		return nil
+	}
+
+	ib, ok := idx.id(b)
+	if !ok {
+		return nil
+	}
+
+	is_ancestor := func(x, y int32) bool {
+		return x <= y && y < x+idx.sizes[x]
+	}
+
+	if is_ancestor(ia, ib) {
+		return a
+	} else if is_ancestor(ib, ia) {
+		return b
+	}
+
+	// Lift a to the highest ancestor that is not an ancestor of b; its parent
+	// is the lowest common ancestor.
+	for k := len(idx.up) - 1; k >= 0; k-- {
+		if anc := idx.up[k][ia]; anc >= 0 && !is_ancestor(anc, ib) {
+			ia = anc
+		}
+	}
+
+	return idx.nodes[idx.up[0][ia]]
+}
@@ -0,0 +1,139 @@
+package tree
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// TestTreeIndex checks on random trees, and on subtrees of them, that the
+// answers of a TreeIndex match those of the naive functions.
+func TestTreeIndex(t *testing.T) {
+	rng := rand.New(rand.NewPCG(16, 16))
+
+	deep := NewBaseNode("Deep", "")
+
+	leaf := deep
+
+	for range 1000 {
+		child := NewBaseNode("Deep", "")
+		_ = leaf.AppendChild(child)
+
+		leaf = child
+	}
+
+	roots := []*BaseNode{NewBaseNode("Leaf", ""), deep, deep.FirstChild.FirstChild}
+
+	for range 200 {
+		root := randomTree(rng, 1+rng.IntN(60))
+
+		roots = append(roots, root)
+
+		// Index a subtree as well, so that depths are relative to a root
+		// that has a parent.
+		if root.FirstChild != nil {
+			roots = append(roots, root.FirstChild)
+		}
+	}
+
+	for _, root := range roots {
+		idx := NewTreeIndex(root)
+		nodes := slices.Collect(PreOrder(root))
+
+		if idx.Len() != Size(root) || idx.Height() != Height(root) {
+			t.Fatalf("Len(), Height() = %d, %d, want %d, %d", idx.Len(), idx.Height(), Size(root), Height(root))
+		}
+
+		for _, n := range nodes {
+			path, _ := Path(root, n)
+
+			if idx.Depth(n) != len(path) {
+				t.Fatalf("Depth(%v) = %d, want %d", n, idx.Depth(n), len(path))
+			}
+
+			if idx.Size(n) != Size(n) {
+				t.Fatalf("Size(%v) = %d, want %d", n, idx.Size(n), Size(n))
+			}
+
+			want := n
+
+			for k := range len(path) + 1 {
+				if got := idx.Ancestor(n, k); got != want {
+					t.Fatalf("Ancestor(%v, %d) = %v, want %v", n, k, got, want)
+				}
+
+				want = want.Parent
+			}
+
+			if idx.Ancestor(n, -1) != nil || idx.Ancestor(n, len(path)+1) != nil {
+				t.Fatalf("Ancestor(%v) out of range did not return nil", n)
+			}
+		}
+
+		for range 50 {
+			a, b := nodes[rng.IntN(len(nodes))], nodes[rng.IntN(len(nodes))]
+
+			want := LCA(a, b)
+
+			if got := idx.LCA(a, b); got != want {
+				t.Fatalf("LCA(%v, %v) = %v, want %v", a, b, got, want)
+			}
+
+			if got := idx.IsAncestor(a, b); got != (want == a) {
+				t.Fatalf("IsAncestor(%v, %v) = %t, want %t", a, b, got, want == a)
+			}
+		}
+	}
+}
+
+// TestTreeIndexUnindexed checks that a nil index and nodes that are nil or not
+// indexed, whether from another tree or outside the indexed subtree, yield the
+// documented zero values.
+func TestTreeIndexUnindexed(t *testing.T) {
+	if NewTreeIndex[string, string](nil) != nil {
+		t.Fatal("NewTreeIndex(nil) != nil")
+	}
+
+	root := mustSExpr(t, `(R (A (X) (Y)) (B))`)
+	a := root.FirstChild
+
+	var nil_idx *TreeIndex[string, string]
+
+	if nil_idx.Len() != 0 || nil_idx.Height() != -1 || nil_idx.Depth(a) != -1 || nil_idx.Size(a) != 0 ||
+		nil_idx.IsAncestor(a, a) || nil_idx.Ancestor(a, 0) != nil || nil_idx.LCA(a, a) != nil {
+		t.Fatal("a nil index does not return the zero values")
+	}
+
+	idx := NewTreeIndex(a)
+	other := mustSExpr(t, `(R (A (X) (Y)) (B))`)
+
+	for _, n := range []*BaseNode{nil, root, root.LastChild, other, other.FirstChild.FirstChild} {
+		if idx.Depth(n) != -1 || idx.Size(n) != 0 || idx.Ancestor(n, 0) != nil {
+			t.Fatalf("Depth(), Size() or Ancestor() of the unindexed %v did not return the zero value", n)
+		}
+
+		if idx.IsAncestor(n, a.FirstChild) || idx.IsAncestor(a, n) {
+			t.Fatalf("IsAncestor() with the unindexed %v = true", n)
+		}
+
+		if idx.LCA(n, a.FirstChild) != nil || idx.LCA(a.FirstChild, n) != nil {
+			t.Fatalf("LCA() with the unindexed %v != nil", n)
+		}
+	}
+
+	if LCA(root, other) != nil || LCA[string, string](root, nil) != nil {
+		t.Fatal("LCA() of nodes of different trees != nil")
+	}
+
+	if _, err := Path(a, root); err == nil {
+		t.Fatal("Path() of a node outside the tree succeeded")
+	}
+
+	if _, err := Path(nil, a); err == nil {
+		t.Fatal("Path() from a nil root succeeded")
+	}
+
+	if _, err := Path(root, nil); err == nil {
+		t.Fatal("Path() of a nil node succeeded")
+	}
+}
@@ -0,0 +1,282 @@
+package tree
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// ErrInvariant occurs when a tree violates one of its structural invariants.
+type ErrInvariant struct {
+	// Path is the path of child indexes from the validated root to the
+	// offending node.
+	Path []int
+
+	// Reason is the invariant that is violated.
+	Reason string
+}
+
+// Error implements error.
+func (e ErrInvariant) Error() string {
+	var builder strings.Builder
+
+	_, _ = builder.WriteString("node at ")
+	_, _ = builder.WriteString(pathString(e.Path))
+	_, _ = builder.WriteRune(' ')
+	_, _ = builder.WriteString(e.Reason)
+
+	str := builder.String()
+	return str
+}
+
+// NewErrInvariant returns an error with the given path and reason.
+//
+// Parameters:
+//   - path: The path of child indexes to the offending node.
+//   - reason: The invariant that is violated.
+//
+// Returns:
+//   - error: An instance of ErrInvariant. Never returns nil.
+//
+// Format:
+//
+//	"node at <path> <reason>"
+//
+// Where:
+//   - <path> is the path of child indexes, written as "/0/2/1". The root is "/".
+//   - <reason> is the invariant that is violated.
+func NewErrInvariant(path []int, reason string) error {
+	e := &ErrInvariant{
+		Path:   path,
+		Reason: reason,
+	}
+
+	return e
+}
+
+// pathString returns the string representation of a path of child indexes.
+//
+// Parameters:
+//   - path: The path of child indexes.
+//
+// Returns:
+//   - string: The path written as "/0/2/1". The empty path is "/".
+func pathString(path []int) string {
+	if len(path) == 0 {
+		return "/"
+	}
+
+	var builder strings.Builder
+
+	for _, idx := range path {
+		_, _ = builder.WriteRune('/')
+		_, _ = builder.WriteString(strconv.Itoa(idx))
+	}
+
+	str := builder.String()
+	return str
+}
+
+// validateFrame is a node pending validation.
+type validateFrame[T comparable, D any] struct {
+	// node is the node to validate.
+	node *GenericNode[T, D]
+
+	// parent is the frame of the node's parent. Nil for the root.
+	parent *validateFrame[T, D]
+
+	// idx is the index of the node among its parent's children.
+	idx int
+}
+
+// path returns the path of child indexes to the node of the frame. It is only
+// built when a violation is reported, so that the walk stays linear on deep
+// trees.
+//
+// Returns:
+//   - []int: The path to the node. Nil for the root.
+func (f *validateFrame[T, D]) path() []int {
+	var path []int
+
+	for ; f.parent != nil; f = f.parent {
+		path = append(path, f.idx)
+	}
+
+	slices.Reverse(path)
+
+	return path
+}
+
+// Validate walks the tree rooted at root and checks that its links are
+// consistent:
+//   - every child's Parent is the node whose children chain contains it;
+//   - PrevSibling and NextSibling are symmetric;
+//   - FirstChild has no PrevSibling and LastChild has no NextSibling and both
+//     match the ends of the children chain;
+//   - no node is reachable twice (no cycles nor shared nodes).
+//
+// The root's own Parent and siblings are not checked so that subtrees can be
+// validated. The walk is not recursive.
+//
+// Parameters:
+//   - root: The root of the tree to validate.
+//
+// Returns:
+//   - error: Nil if the tree is consistent. Otherwise, every violation joined
+//     with errors.Join.
+//
+// Errors:
+//   - *ErrInvariant: For each violated invariant.
+func Validate[T comparable, D any](root *GenericNode[T, D]) error {
+	if root == nil {
+		return nil
+	}
+
+	var errs []error
+
+	seen := map[*GenericNode[T, D]]struct{}{
+		root: {},
+	}
+
+	stack := []*validateFrame[T, D]{{node: root}}
+
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		n := top.node
+
+		if n.FirstChild == nil || n.LastChild == nil {
+			if n.FirstChild != n.LastChild {
+				errs = append(errs, NewErrInvariant(top.path(), "has only one of FirstChild and LastChild set"))
+			}
+
+			continue
+		}
+
+		if n.FirstChild.PrevSibling != nil {
+			errs = append(errs, NewErrInvariant(top.path(), "has a FirstChild with a PrevSibling"))
+		}
+
+		var children []*validateFrame[T, D]
+
+		var last *GenericNode[T, D]
+
+		var broken bool
+
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			frame := &validateFrame[T, D]{node: child, parent: top, idx: len(children)}
+
+			if _, ok := seen[child]; ok {
+				errs = append(errs, NewErrInvariant(frame.path(), "is reachable more than once (cycle or shared node)"))
+				broken = true
+
+				break
+			}
+
+			seen[child] = struct{}{}
+
+			if child.Parent != n {
+				errs = append(errs, NewErrInvariant(frame.path(), "has a Parent that is not the node whose children contain it"))
+			}
+
+			if child.NextSibling != nil && child.NextSibling.PrevSibling != child {
+				errs = append(errs, NewErrInvariant(frame.path(), "has a NextSibling whose PrevSibling is not the node"))
+			}
+
+			children = append(children, frame)
+			last = child
+		}
+
+		if !broken && last != n.LastChild {
+			errs = append(errs, NewErrInvariant(top.path(), "has a LastChild that is not the end of its children chain"))
+		}
+
+		for i := len(children) - 1; i >= 0; i-- {
+			stack = append(stack, children[i])
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errors.Join(errs...)
+}
+
+var (
+	// debug is true if the mutating operations validate the trees they modify.
+	debug atomic.Bool
+)
+
+// SetDebug enables or disables the debug mode. In debug mode, every mutating
+// operation on nodes validates the trees it modifies with Validate and returns
+// the violations, so that corruption is caught where it originates. This is
+// expensive and is meant for tests and debugging only.
+//
+// Parameters:
+//   - enabled: True to enable the debug mode, false to disable it.
+func SetDebug(enabled bool) {
+	debug.Store(enabled)
+}
+
+// IsDebug checks whether the debug mode is enabled.
+//
+// Returns:
+//   - bool: True if the debug mode is enabled, false otherwise.
+func IsDebug() bool {
+	ok := debug.Load()
+	return ok
+}
+
+// debugValidate validates the trees containing the given nodes if the debug
+// mode is enabled. Nil nodes are ignored.
+//
+// Parameters:
+//   - nodes: The nodes whose trees are validated.
+//
+// Returns:
+//   - error: The violations found, if any.
+func debugValidate[T comparable, D any](nodes ...*GenericNode[T, D]) error {
+	if !debug.Load() {
+		return nil
+	}
+
+	var errs []error
+
+	done := make(map[*GenericNode[T, D]]struct{})
+
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+
+		root := node
+		seen := map[*GenericNode[T, D]]struct{}{node: {}}
+
+		for root.Parent != nil {
+			if _, ok := seen[root.Parent]; ok {
+				errs = append(errs, NewErrInvariant(nil, "is part of a Parent cycle"))
+				break
+			}
+
+			root = root.Parent
+			seen[root] = struct{}{}
+		}
+
+		if _, ok := done[root]; ok {
+			continue
+		}
+
+		done[root] = struct{}{}
+
+		err := Validate(root)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,209 @@
+package tree
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// invariants returns the messages of the violations reported by Validate.
+//
+// Parameters:
+//   - err: The error returned by Validate.
+//
+// Returns:
+//   - []string: The messages of the *ErrInvariant errors, in order.
+func invariants(err error) []string {
+	if err == nil {
+		return nil
+	}
+
+	errs := []error{err}
+
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+
+	var msgs []string
+
+	for _, err := range errs {
+		var inv *ErrInvariant
+
+		if errors.As(err, &inv) {
+			msgs = append(msgs, inv.Error())
+		}
+	}
+
+	return msgs
+}
+
+// TestValidate checks the violations reported for trees broken by hand.
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		break_ func(root *BaseNode)
+		want   []string
+	}{
+		{
+			name:   "Valid",
+			break_: func(root *BaseNode) {},
+		},
+		{
+			name: "BadParent",
+			break_: func(root *BaseNode) {
+				root.LastChild.Parent = root.FirstChild
+			},
+			want: []string{"node at /1 has a Parent that is not the node whose children contain it"},
+		},
+		{
+			name: "BadPrevSibling",
+			break_: func(root *BaseNode) {
+				root.LastChild.PrevSibling = nil
+			},
+			want: []string{"node at /0 has a NextSibling whose PrevSibling is not the node"},
+		},
+		{
+			name: "FirstChildWithPrevSibling",
+			break_: func(root *BaseNode) {
+				root.FirstChild.PrevSibling = root.LastChild
+			},
+			want: []string{"node at / has a FirstChild with a PrevSibling"},
+		},
+		{
+			name: "BadLastChild",
+			break_: func(root *BaseNode) {
+				root.LastChild = root.FirstChild
+			},
+			want: []string{"node at / has a LastChild that is not the end of its children chain"},
+		},
+		{
+			name: "OnlyFirstChild",
+			break_: func(root *BaseNode) {
+				root.FirstChild.FirstChild.LastChild = NewBaseNode("Y", "")
+			},
+			want: []string{"node at /0/0 has only one of FirstChild and LastChild set"},
+		},
+		{
+			name: "ChildCycle",
+			break_: func(root *BaseNode) {
+				x := root.FirstChild.FirstChild
+
+				x.FirstChild = root
+				x.LastChild = root
+			},
+			want: []string{"node at /0/0/0 is reachable more than once (cycle or shared node)"},
+		},
+		{
+			name: "SiblingCycle",
+			break_: func(root *BaseNode) {
+				root.LastChild.NextSibling = root.FirstChild
+			},
+			want: []string{
+				"node at /1 has a NextSibling whose PrevSibling is not the node",
+				"node at /2 is reachable more than once (cycle or shared node)",
+			},
+		},
+		{
+			name: "SharedNode",
+			break_: func(root *BaseNode) {
+				x := root.FirstChild.FirstChild
+				b := root.LastChild
+
+				b.FirstChild = x
+				b.LastChild = x
+			},
+			want: []string{"node at /1/0 is reachable more than once (cycle or shared node)"},
+		},
+	}
+
+	for _, tt := range tests {
+		root := mustSExpr(t, `(R (A (X)) (B))`)
+
+		tt.break_(root)
+
+		if got := invariants(Validate(root)); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: Validate() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestValidateDeep checks that Validate handles deep trees in linear time and
+// reports the full path of a violation.
+func TestValidateDeep(t *testing.T) {
+	root := NewBaseNode("N", "")
+
+	leaf := root
+
+	for range 100000 {
+		child := NewBaseNode("N", "")
+		_ = leaf.AppendChild(child)
+
+		leaf = child
+	}
+
+	err := Validate(root)
+	if err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+
+	leaf.Parent = nil
+
+	var inv *ErrInvariant
+
+	if !errors.As(Validate(root), &inv) || len(inv.Path) != 100000 {
+		t.Fatalf("Validate() did not report the path to the deepest node")
+	}
+}
+
+// TestDebugMode checks that, in debug mode, every mutation validates the trees
+// it touches and reports their violations, and that nothing is validated
+// otherwise.
+func TestDebugMode(t *testing.T) {
+	SetDebug(true)
+	defer SetDebug(false)
+
+	if !IsDebug() {
+		t.Fatal("IsDebug() = false after SetDebug(true)")
+	}
+
+	root := mustSExpr(t, `(R (A (X)) (B))`)
+	a, b := root.FirstChild, root.LastChild
+
+	mutations := map[string]func() error{
+		"AppendChild":  func() error { return b.AppendChild(NewBaseNode("C", "")) },
+		"PrependChild": func() error { return b.PrependChild(NewBaseNode("C", "")) },
+		"InsertBefore": func() error { return b.InsertBefore(NewBaseNode("C", "")) },
+		"InsertAfter":  func() error { return b.InsertAfter(NewBaseNode("C", "")) },
+		"ReplaceWith":  func() error { return b.FirstChild.ReplaceWith(NewBaseNode("C", "")) },
+		"RemoveChild":  func() error { return b.RemoveChild(b.FirstChild) },
+		"Detach":       func() error { return b.LastChild.Detach() },
+	}
+
+	names := []string{"AppendChild", "PrependChild", "InsertBefore", "InsertAfter", "ReplaceWith", "RemoveChild", "Detach"}
+
+	for _, name := range names {
+		err := mutations[name]()
+		if err != nil {
+			t.Fatalf("%s() on a valid tree = %v", name, err)
+		}
+	}
+
+	// Corrupting the subtree of A makes every mutation of the tree fail.
+	a.FirstChild.Parent = b
+
+	var inv *ErrInvariant
+
+	for _, name := range names {
+		err := mutations[name]()
+		if !errors.As(err, &inv) {
+			t.Errorf("%s() on a corrupted tree = %v, want an *ErrInvariant", name, err)
+		}
+	}
+
+	SetDebug(false)
+
+	err := b.AppendChild(NewBaseNode("C", ""))
+	if err != nil {
+		t.Fatalf("AppendChild() outside of debug mode = %v, want nil", err)
+	}
+}